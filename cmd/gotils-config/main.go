@@ -0,0 +1,407 @@
+// gotils-config inspects, validates and converts configs loaded by the config package.
+//
+// Usage:
+//
+//	gotils-config get [source flags] KEY...
+//	gotils-config list [source flags] [-format env|json|yaml|toml] [PREFIX]
+//	gotils-config validate [source flags] -schema FILE
+//	gotils-config convert [-profile NAME] [-to FORMAT] [-o FILE] FILE
+//	gotils-config diff [-profile NAME] FILE FILE
+//	gotils-config explain [source flags] [KEY...]
+//
+// Source flags are -env PREFIX and -file PATH, both repeatable, and -profile NAME.
+// Values are loaded like NewLoadedConfig does, environment variables take precedence over files.
+// Env files are read in dotenv mode, so quoted values written by convert load back unchanged.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/myLogic207/gotils/config"
+	_ "github.com/myLogic207/gotils/config/formats"
+	"github.com/myLogic207/gotils/config/schema"
+)
+
+var (
+	// errUsage is returned for invalid arguments, the usage was already printed
+	errUsage = errors.New("invalid usage")
+	// errCheckFailed is returned if a check failed, the failures were already printed
+	errCheckFailed = errors.New("check failed")
+)
+
+type commandFunc func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+
+var commands = map[string]commandFunc{
+	"get":      runGet,
+	"list":     runList,
+	"validate": runValidate,
+	"convert":  runConvert,
+	"diff":     runDiff,
+	"explain":  runExplain,
+}
+
+var usages = map[string]string{
+	"get":      "get [source flags] KEY...",
+	"list":     "list [source flags] [-format env|json|yaml|toml] [PREFIX]",
+	"validate": "validate [source flags] -schema FILE",
+	"convert":  "convert [-profile NAME] [-to FORMAT] [-o FILE] FILE",
+	"diff":     "diff [-profile NAME] FILE FILE",
+	"explain":  "explain [source flags] [KEY...]",
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the exit code,
+// 1 if the command or a check failed and 2 for invalid usage
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] != "help" && args[0] != "-h" && args[0] != "-help" {
+			fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		}
+		printUsage(stderr)
+		return 2
+	}
+	err := cmd(ctx, args[1:], stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp):
+		return 2
+	case errors.Is(err, errCheckFailed):
+		return 1
+	default:
+		fmt.Fprintln(stderr, "gotils-config:", err)
+		return 1
+	}
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fmt.Fprintln(w, "usage:")
+	for _, name := range names {
+		fmt.Fprintln(w, "  gotils-config", usages[name])
+	}
+	fmt.Fprintln(w, "source flags: -env PREFIX and -file PATH (repeatable), -profile NAME")
+}
+
+// listFlag collects the values of a repeatable flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// sources are the environment prefixes and files a config is loaded from
+type sources struct {
+	env     listFlag
+	files   listFlag
+	profile string
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gotils-config", usages[name])
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func (s *sources) register(flags *flag.FlagSet) {
+	flags.Var(&s.env, "env", "environment variable `prefix`, repeatable")
+	flags.Var(&s.files, "file", "config file or directory `path`, repeatable")
+	flags.StringVar(&s.profile, "profile", "", "profile `name` applied while loading")
+}
+
+func (s *sources) load(ctx context.Context) (*config.Config, error) {
+	if len(s.env) == 0 && len(s.files) == 0 {
+		return nil, config.ErrNoConfigSource
+	}
+	return loadConfig(ctx, s.env, s.files, s.profile)
+}
+
+func loadConfig(ctx context.Context, envPrefixList []string, fileList []string, profile string) (*config.Config, error) {
+	// env dumps quote values for the dotenv parser
+	opts := []config.Option{config.WithLoader(&config.ConfigLoader{Dotenv: true})}
+	if profile != "" {
+		opts = append(opts, config.WithProfile(profile))
+	}
+	return config.NewLoadedConfig(ctx, envPrefixList, fileList, opts...)
+}
+
+// parse parses the flags of a command, usage errors are already printed by the flag set
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+func usageError(flags *flag.FlagSet, message string) error {
+	fmt.Fprintln(flags.Output(), message)
+	flags.Usage()
+	return errUsage
+}
+
+func runGet(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("get", stderr)
+	src := &sources{}
+	src.register(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError(flags, "missing key")
+	}
+	cfg, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, key := range flags.Args() {
+		value, err := cfg.Get(ctx, key)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			failed = true
+			continue
+		}
+		fmt.Fprintln(stdout, value)
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+func runList(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("list", stderr)
+	src := &sources{}
+	src.register(flags)
+	format := flags.String("format", config.FORMAT_ENV, "output `format`: "+strings.Join(config.Formats(), ", "))
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return usageError(flags, "too many arguments")
+	}
+	cfg, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	if prefix := flags.Arg(0); prefix != "" {
+		cfg = &config.Config{ConfigStore: cfg.View(prefix)}
+	}
+	return cfg.Dump(ctx, stdout, *format)
+}
+
+func runValidate(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("validate", stderr)
+	src := &sources{}
+	src.register(flags)
+	schemaPath := flags.String("schema", "", "JSON schema `file` to validate against")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return usageError(flags, "missing -schema")
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags, "unexpected arguments")
+	}
+	schemaFile, err := os.Open(*schemaPath)
+	if err != nil {
+		return err
+	}
+	defer schemaFile.Close()
+	validator, err := schema.Load(schemaFile)
+	if err != nil {
+		return err
+	}
+	cfg, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validator.Validate(ctx, cfg); errors.Is(err, config.ErrValueInvalid) {
+		fmt.Fprintln(stderr, err)
+		return errCheckFailed
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func runConvert(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("convert", stderr)
+	profile := flags.String("profile", "", "profile `name` applied while loading")
+	format := flags.String("to", "", "output `format`: "+strings.Join(config.Formats(), ", ")+", defaults to the extension of -o")
+	output := flags.String("o", "", "output `file`, defaults to stdout")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags, "expected exactly one input file")
+	}
+	if *format == "" {
+		if *output == "" {
+			return usageError(flags, "missing -to or -o")
+		}
+		*format = config.FormatFromPath(*output)
+	}
+	if !slices.Contains(config.Formats(), *format) {
+		return usageError(flags, "unknown format "+*format)
+	}
+	cfg, err := loadConfig(ctx, nil, flags.Args(), *profile)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		return cfg.DumpToFile(ctx, *format, *output)
+	}
+	return cfg.Dump(ctx, stdout, *format)
+}
+
+// runDiff prints keys missing in the second file with '-', added keys with '+'
+// and changed keys with both, differences fail the check
+func runDiff(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("diff", stderr)
+	profile := flags.String("profile", "", "profile `name` applied while loading")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError(flags, "expected two files")
+	}
+	before, err := loadConfig(ctx, nil, flags.Args()[:1], *profile)
+	if err != nil {
+		return err
+	}
+	after, err := loadConfig(ctx, nil, flags.Args()[1:], *profile)
+	if err != nil {
+		return err
+	}
+	keys := append(before.Keys(ctx), after.Keys(ctx)...)
+	slices.SortFunc(keys, config.CompareKeys)
+	keys = slices.Compact(keys)
+	changed := false
+	for _, key := range keys {
+		oldValue, oldErr := before.Get(ctx, key)
+		newValue, newErr := after.Get(ctx, key)
+		if oldErr == nil && newErr == nil && oldValue == newValue {
+			continue
+		}
+		changed = true
+		if oldErr == nil {
+			fmt.Fprintf(stdout, "-%s=%s\n", key, quoteValue(oldValue))
+		}
+		if newErr == nil {
+			fmt.Fprintf(stdout, "+%s=%s\n", key, quoteValue(newValue))
+		}
+	}
+	if changed {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// quoteValue quotes values that would not fit on a single diff line or lose white space
+func quoteValue(value string) string {
+	if strings.ContainsAny(value, "\r\n\"") || strings.TrimSpace(value) != value {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// source is a single environment prefix or file of a config
+type source struct {
+	name   string
+	config *config.Config
+}
+
+// runExplain prints the effective value of keys with the source it was loaded from
+func runExplain(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := newFlagSet("explain", stderr)
+	src := &sources{}
+	src.register(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	cfg, err := src.load(ctx)
+	if err != nil {
+		return err
+	}
+	// sources in order of precedence, environment variables win over files and later files over earlier ones
+	sourceList := []source{}
+	for _, prefix := range src.env {
+		prefixConfig, err := loadConfig(ctx, []string{prefix}, nil, src.profile)
+		if err != nil {
+			return err
+		}
+		sourceList = append(sourceList, source{name: "env " + prefix, config: prefixConfig})
+	}
+	for i := len(src.files) - 1; i >= 0; i-- {
+		fileConfig, err := loadConfig(ctx, nil, []string{src.files[i]}, src.profile)
+		if err != nil {
+			return err
+		}
+		sourceList = append(sourceList, source{name: "file " + src.files[i], config: fileConfig})
+	}
+
+	keys := flags.Args()
+	if len(keys) == 0 {
+		keys = cfg.Keys(ctx)
+	}
+	writer := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	failed := false
+	for _, key := range keys {
+		value, err := cfg.Get(ctx, key)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			failed = true
+			continue
+		}
+		origin := "unknown"
+		for _, s := range sourceList {
+			if s.config.Has(ctx, key) {
+				origin = s.name
+				break
+			}
+		}
+		fmt.Fprintf(writer, "%s=%s\t%s\n", strings.ToUpper(key), value, origin)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
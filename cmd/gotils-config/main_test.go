@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	filePath := filepath.Join(dir, name)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	code := run(context.TODO(), args, stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestGetAndList(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "app.env", "SERVER_HOST=localhost\nSERVER_PORT=8080\nNAME=app\n")
+	t.Setenv("CLITEST_SERVER_PORT", "9090")
+
+	code, stdout, stderr := runCommand(t, "get", "-env", "CLITEST", "-file", file, "SERVER/HOST", "server/port")
+	if code != 0 || stdout != "localhost\n9090\n" {
+		t.Errorf("Unexpected get result %d %q %q", code, stdout, stderr)
+	}
+	if code, _, stderr := runCommand(t, "get", "-file", file, "MISSING"); code != 1 || stderr == "" {
+		t.Errorf("Expected missing key to fail, got %d %q", code, stderr)
+	}
+
+	code, stdout, _ = runCommand(t, "list", "-file", file, "SERVER")
+	if code != 0 || stdout != "HOST=localhost\nPORT=8080\n" {
+		t.Errorf("Unexpected list result %d %q", code, stdout)
+	}
+	code, stdout, _ = runCommand(t, "list", "-file", file, "-format", "json", "SERVER")
+	if code != 0 || !strings.Contains(stdout, `"HOST": "localhost"`) {
+		t.Errorf("Unexpected json list result %d %q", code, stdout)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	schema := writeFile(t, dir, "schema.json", `{"properties": {"port": {"type": "integer"}}, "required": ["port"]}`)
+	valid := writeFile(t, dir, "valid.env", "PORT=8080\n")
+	invalid := writeFile(t, dir, "invalid.env", "PORT=http\n")
+
+	if code, _, stderr := runCommand(t, "validate", "-schema", schema, "-file", valid); code != 0 {
+		t.Errorf("Expected valid config, got %d %q", code, stderr)
+	}
+	code, _, stderr := runCommand(t, "validate", "-schema", schema, "-file", invalid)
+	if code != 1 || !strings.Contains(stderr, "PORT") {
+		t.Errorf("Expected violation, got %d %q", code, stderr)
+	}
+	if code, _, _ := runCommand(t, "validate", "-file", valid); code != 2 {
+		t.Errorf("Expected usage error without schema, got %d", code)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	input := writeFile(t, dir, "app.yaml", "server:\n  host: localhost\n  port: 8080\n")
+
+	code, stdout, stderr := runCommand(t, "convert", "-to", "toml", input)
+	if code != 0 || stdout != "[SERVER]\nHOST = \"localhost\"\nPORT = \"8080\"\n" {
+		t.Errorf("Unexpected convert result %d %q %q", code, stdout, stderr)
+	}
+
+	output := filepath.Join(dir, "app.json")
+	if code, _, stderr := runCommand(t, "convert", "-o", output, input); code != 0 {
+		t.Fatalf("Unexpected convert result %d %q", code, stderr)
+	}
+	code, stdout, _ = runCommand(t, "get", "-file", output, "SERVER/PORT")
+	if code != 0 || stdout != "8080\n" {
+		t.Errorf("Converted file not loadable %d %q", code, stdout)
+	}
+	if code, _, _ := runCommand(t, "convert", "-to", "xml", input); code != 2 {
+		t.Errorf("Expected usage error for unknown format, got %d", code)
+	}
+}
+
+func TestConvertEnvRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := writeFile(t, dir, "in.json", `{"msg": "hello # world", "text": "line one\nline two", "pad": " x ", "quote": "say \"hi\""}`)
+	output := filepath.Join(dir, "out.env")
+	if code, _, stderr := runCommand(t, "convert", "-to", "env", "-o", output, input); code != 0 {
+		t.Fatalf("Unexpected convert result %d %q", code, stderr)
+	}
+	for key, expected := range map[string]string{
+		"MSG":   "hello # world",
+		"TEXT":  "line one\nline two",
+		"PAD":   " x ",
+		"QUOTE": `say "hi"`,
+	} {
+		if code, stdout, _ := runCommand(t, "get", "-file", output, key); code != 0 || stdout != expected+"\n" {
+			t.Errorf("Expected %q for %s, got %d %q", expected, key, code, stdout)
+		}
+	}
+	if code, stdout, _ := runCommand(t, "diff", input, output); code != 0 || stdout != "" {
+		t.Errorf("Expected converted file to equal the input, got %d %q", code, stdout)
+	}
+	// multi-line values are printed on a single diff line
+	changed := writeFile(t, dir, "changed.env", "MSG=\"hello\nworld\"\n")
+	if code, stdout, _ := runCommand(t, "diff", output, changed); code != 1 || !strings.Contains(stdout, "+MSG=\"hello\\nworld\"\n") {
+		t.Errorf("Expected quoted multi-line value, got %d %q", code, stdout)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := writeFile(t, dir, "before.env", "A=1\nB=2\nC=3\n")
+	after := writeFile(t, dir, "after.env", "A=1\nB=changed\nD=4\n")
+
+	code, stdout, _ := runCommand(t, "diff", before, after)
+	if code != 1 || stdout != "-B=2\n+B=changed\n-C=3\n+D=4\n" {
+		t.Errorf("Unexpected diff result %d %q", code, stdout)
+	}
+	if code, stdout, _ := runCommand(t, "diff", before, before); code != 0 || stdout != "" {
+		t.Errorf("Expected no differences, got %d %q", code, stdout)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	base := writeFile(t, dir, "base.env", "HOST=base\nPORT=1\nNAME=app\n")
+	override := writeFile(t, dir, "override.env", "PORT=2\n")
+	t.Setenv("EXPLAINTEST_HOST", "env")
+
+	code, stdout, stderr := runCommand(t, "explain", "-env", "EXPLAINTEST", "-file", base, "-file", override)
+	if code != 0 {
+		t.Fatalf("Unexpected explain result %d %q", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	expected := []string{
+		"HOST=env  env EXPLAINTEST",
+		"NAME=app  file " + base,
+		"PORT=2    file " + override,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %q", len(expected), stdout)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], line)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if code, _, stderr := runCommand(t); code != 2 || !strings.Contains(stderr, "usage:") {
+		t.Errorf("Expected usage, got %d %q", code, stderr)
+	}
+	if code, _, stderr := runCommand(t, "unknown"); code != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Errorf("Expected unknown command, got %d %q", code, stderr)
+	}
+	if code, _, stderr := runCommand(t, "get", "SOME/KEY"); code != 1 || !strings.Contains(stderr, "no config source") {
+		t.Errorf("Expected missing source error, got %d %q", code, stderr)
+	}
+}
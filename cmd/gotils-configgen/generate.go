@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/myLogic207/gotils/config"
+	"github.com/myLogic207/gotils/config/schema"
+)
+
+type fieldKind int
+
+const (
+	KIND_VALUE fieldKind = iota
+	KIND_SLICE
+	KIND_STRUCT
+	KIND_STRUCT_SLICE
+)
+
+// goStruct is a generated struct, nested keys become nested structs
+type goStruct struct {
+	name   string
+	fields []*goField
+}
+
+type goField struct {
+	name string
+	// key is the segment below the key of the parent struct
+	key      string
+	kind     fieldKind
+	typeName string
+	nested   *goStruct
+	required bool
+	doc      string
+}
+
+func (f *goField) goType() string {
+	switch f.kind {
+	case KIND_SLICE:
+		return "[]" + f.typeName
+	case KIND_STRUCT:
+		return f.nested.name
+	case KIND_STRUCT_SLICE:
+		return "[]" + f.nested.name
+	default:
+		return f.typeName
+	}
+}
+
+// initialisms are written in upper case in field names
+var initialisms = []string{"API", "DB", "DNS", "HTTP", "HTTPS", "ID", "IP", "JSON", "SQL", "TCP", "TLS", "TTL", "UDP", "URI", "URL", "UUID"}
+
+// goName converts a key segment like LOG_LEVEL to an exported Go name like LogLevel
+func goName(segment string) string {
+	builder := strings.Builder{}
+	parts := strings.FieldsFunc(segment, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, part := range parts {
+		upper := strings.ToUpper(part)
+		if slices.Contains(initialisms, upper) {
+			builder.WriteString(upper)
+			continue
+		}
+		builder.WriteString(upper[:1] + strings.ToLower(upper[1:]))
+	}
+	name := builder.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// fromSample derives a struct from the values of a loaded sample config
+func fromSample(ctx context.Context, cfg *config.Config, prefix string, name string) (*goStruct, error) {
+	result := &goStruct{name: name}
+	for _, segment := range cfg.Children(ctx, prefix) {
+		key := joinKey(prefix, segment)
+		field := &goField{name: goName(segment), key: segment, required: true}
+		children := cfg.Children(ctx, key)
+		switch {
+		case len(children) == 0:
+			value, err := cfg.Get(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			field.kind, field.typeName = KIND_VALUE, inferType(value)
+		case isIndexList(children):
+			items := []*goStruct{}
+			for _, index := range children {
+				itemKey := joinKey(key, index)
+				if len(cfg.Children(ctx, itemKey)) == 0 {
+					value, err := cfg.Get(ctx, itemKey)
+					if err != nil {
+						return nil, err
+					}
+					field.kind, field.typeName = KIND_SLICE, unifyTypes(field.typeName, inferType(value))
+					continue
+				}
+				item, err := fromSample(ctx, cfg, itemKey, name+field.name)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			if len(items) > 0 && field.kind == KIND_SLICE {
+				return nil, fmt.Errorf("list %s mixes values and nested keys", key)
+			}
+			if len(items) > 0 {
+				field.kind, field.nested = KIND_STRUCT_SLICE, mergeStructs(items)
+			}
+		default:
+			nested, err := fromSample(ctx, cfg, key, name+field.name)
+			if err != nil {
+				return nil, err
+			}
+			field.kind, field.nested = KIND_STRUCT, nested
+		}
+		if err := result.add(field); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// fromSchema derives a struct from the properties of an object schema,
+// only keys listed as required are required when decoding
+func fromSchema(object *schema.Schema, name string) (*goStruct, error) {
+	result := &goStruct{name: name}
+	names := make([]string, 0, len(object.Properties))
+	for property := range object.Properties {
+		names = append(names, property)
+	}
+	slices.Sort(names)
+	for _, property := range names {
+		propertySchema := object.Properties[property]
+		if propertySchema == nil {
+			propertySchema = &schema.Schema{}
+		}
+		field := &goField{
+			name:     goName(property),
+			key:      strings.ToUpper(property),
+			required: slices.ContainsFunc(object.Required, func(required string) bool { return strings.EqualFold(required, property) }),
+			doc:      propertySchema.Description,
+		}
+		switch {
+		case isObjectSchema(propertySchema):
+			nested, err := fromSchema(propertySchema, name+field.name)
+			if err != nil {
+				return nil, err
+			}
+			field.kind, field.nested = KIND_STRUCT, nested
+		case propertySchema.Type == "array" && propertySchema.Items != nil && isObjectSchema(propertySchema.Items):
+			nested, err := fromSchema(propertySchema.Items, name+field.name)
+			if err != nil {
+				return nil, err
+			}
+			field.kind, field.nested = KIND_STRUCT_SLICE, nested
+		case propertySchema.Type == "array":
+			field.kind, field.typeName = KIND_SLICE, "string"
+			if propertySchema.Items != nil {
+				field.typeName = schemaType(propertySchema.Items.Type)
+			}
+		default:
+			field.kind, field.typeName = KIND_VALUE, schemaType(propertySchema.Type)
+		}
+		if err := result.add(field); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func isObjectSchema(s *schema.Schema) bool {
+	return s.Type == "object" || s.Type == "" && len(s.Properties) > 0
+}
+
+func schemaType(schemaType string) string {
+	switch schemaType {
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func (s *goStruct) add(field *goField) error {
+	for _, existing := range s.fields {
+		if existing.name == field.name {
+			return fmt.Errorf("keys %s and %s of %s both map to field %s", existing.key, field.key, s.name, field.name)
+		}
+	}
+	s.fields = append(s.fields, field)
+	return nil
+}
+
+// inferType returns the Go type a sample value is decoded to
+func inferType(value string) string {
+	if lower := strings.ToLower(value); lower == "true" || lower == "false" {
+		return "bool"
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && strings.Trim(value, "0123456789.eE+-") == "" {
+		return "float64"
+	}
+	if _, err := config.ParseDuration(value); err == nil {
+		return "time.Duration"
+	}
+	return "string"
+}
+
+// unifyTypes returns a type able to hold values of both types
+func unifyTypes(a string, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case a == "int" && b == "float64" || a == "float64" && b == "int":
+		return "float64"
+	default:
+		return "string"
+	}
+}
+
+// mergeStructs combines the structs of list items, fields missing in some items are optional
+func mergeStructs(items []*goStruct) *goStruct {
+	merged := &goStruct{name: items[0].name}
+	for _, item := range items {
+		for _, field := range item.fields {
+			index := slices.IndexFunc(merged.fields, func(existing *goField) bool { return existing.key == field.key })
+			if index < 0 {
+				clone := *field
+				merged.fields = append(merged.fields, &clone)
+				continue
+			}
+			existing := merged.fields[index]
+			switch {
+			case existing.kind != field.kind:
+				existing.kind, existing.typeName, existing.nested = KIND_VALUE, "string", nil
+			case existing.nested != nil:
+				existing.nested = mergeStructs([]*goStruct{existing.nested, field.nested})
+			default:
+				existing.typeName = unifyTypes(existing.typeName, field.typeName)
+			}
+		}
+	}
+	for _, field := range merged.fields {
+		for _, item := range items {
+			if !slices.ContainsFunc(item.fields, func(f *goField) bool { return f.key == field.key }) {
+				field.required = false
+			}
+		}
+	}
+	slices.SortFunc(merged.fields, func(a, b *goField) int { return strings.Compare(a.key, b.key) })
+	return merged
+}
+
+func isIndexList(children []string) bool {
+	for i := range children {
+		if !slices.Contains(children, strconv.Itoa(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+func joinKey(base string, key string) string {
+	if base == "" {
+		return key
+	}
+	return base + config.CONFIG_TREE_SEPARATOR + key
+}
+
+// generator holds the settings of the generated file
+type generator struct {
+	packageName string
+	source      string
+	envPrefixes []string
+	files       []string
+}
+
+// generate writes the formatted source of root and all nested structs
+// with Load<root> and Decode<root> functions
+func (g *generator) generate(root *goStruct) ([]byte, error) {
+	structs := []*goStruct{}
+	collectStructs(root, &structs)
+	usesTime, usesStrconv, usesReflect := false, false, false
+	for _, s := range structs {
+		usesReflect = usesReflect || len(s.fields) > 0
+		for _, field := range s.fields {
+			usesTime = usesTime || strings.HasPrefix(field.typeName, "time.")
+			usesStrconv = usesStrconv || field.kind == KIND_STRUCT_SLICE
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "// Code generated by gotils-configgen from %s; DO NOT EDIT.\n\n", g.source)
+	fmt.Fprintf(b, "package %s\n\nimport (\n\t\"context\"\n", g.packageName)
+	if usesReflect {
+		b.WriteString("\t\"reflect\"\n")
+	}
+	if usesStrconv {
+		b.WriteString("\t\"strconv\"\n")
+	}
+	if usesTime {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString("\n\t\"github.com/myLogic207/gotils/config\"\n")
+	if g.usesFormats() {
+		b.WriteString("\t_ \"github.com/myLogic207/gotils/config/formats\"\n")
+	}
+	b.WriteString(")\n\n")
+
+	for _, s := range structs {
+		g.writeStruct(b, s)
+	}
+
+	fmt.Fprintf(b, "var (\n\t%sEnvPrefixes = %s\n\t%sFiles = %s\n)\n\n",
+		lowerFirst(root.name), quoteList(g.envPrefixes), lowerFirst(root.name), quoteList(g.files))
+	// the functions are named after the struct, so several generated structs fit in one package
+	fmt.Fprintf(b, "// Load%s reads the config with config.NewLoadedConfig and decodes it into %s\n", root.name, root.name)
+	fmt.Fprintf(b, "func Load%s(ctx context.Context, opts ...config.Option) (*%s, error) {\n", root.name, root.name)
+	fmt.Fprintf(b, "\tloaded, err := config.NewLoadedConfig(ctx, %sEnvPrefixes, %sFiles, opts...)\n", lowerFirst(root.name), lowerFirst(root.name))
+	fmt.Fprintf(b, "\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn Decode%s(ctx, loaded)\n}\n\n", root.name)
+	fmt.Fprintf(b, "// Decode%s reads %s from store, missing required keys return a *config.ErrKeyNotFound\n", root.name, root.name)
+	fmt.Fprintf(b, "func Decode%s(ctx context.Context, store config.ConfigStore) (*%s, error) {\n", root.name, root.name)
+	fmt.Fprintf(b, "\tvalue := &%s{}\n", root.name)
+	b.WriteString("\tif err := value.decode(ctx, store, \"\"); err != nil {\n\t\treturn nil, err\n\t}\n\treturn value, nil\n}\n")
+
+	for _, s := range structs {
+		g.writeDecode(b, s)
+	}
+	return format.Source([]byte(b.String()))
+}
+
+// usesFormats checks if a loaded file needs the formats package, like yaml or toml files
+func (g *generator) usesFormats() bool {
+	for _, file := range g.files {
+		if format := config.FormatFromPath(file); format != config.FORMAT_ENV && format != config.FORMAT_JSON {
+			return true
+		}
+	}
+	return false
+}
+
+func collectStructs(s *goStruct, structs *[]*goStruct) {
+	*structs = append(*structs, s)
+	for _, field := range s.fields {
+		if field.nested != nil {
+			collectStructs(field.nested, structs)
+		}
+	}
+}
+
+func (g *generator) writeStruct(b *strings.Builder, s *goStruct) {
+	fmt.Fprintf(b, "type %s struct {\n", s.name)
+	for _, field := range s.fields {
+		if field.doc != "" {
+			fmt.Fprintf(b, "\t// %s\n", strings.ReplaceAll(field.doc, "\n", "\n\t// "))
+		}
+		fmt.Fprintf(b, "\t%s %s `config:%q`\n", field.name, field.goType(), field.key)
+	}
+	b.WriteString("}\n\n")
+}
+
+// writeDecode writes the decode method of s, keys are read from the config tags of the fields
+func (g *generator) writeDecode(b *strings.Builder, s *goStruct) {
+	fmt.Fprintf(b, "\nfunc (v *%s) decode(ctx context.Context, store config.ConfigStore, prefix string) error {\n", s.name)
+	if len(s.fields) > 0 {
+		b.WriteString("\tfields := reflect.TypeOf(*v)\n")
+		b.WriteString("\tkey := func(i int) string { return prefix + fields.Field(i).Tag.Get(\"config\") }\n")
+		b.WriteString("\tvar err error\n")
+	}
+	for i, field := range s.fields {
+		key := fmt.Sprintf("key(%d)", i)
+		var decode string
+		switch field.kind {
+		case KIND_STRUCT:
+			decode = fmt.Sprintf("if err = v.%s.decode(ctx, store, %s+%q); err != nil {\n\t\treturn err\n\t}\n",
+				field.name, key, config.CONFIG_TREE_SEPARATOR)
+		case KIND_STRUCT_SLICE:
+			fmt.Fprintf(b, "\tfor i := 0; store.Has(ctx, %s+%q+strconv.Itoa(i)); i++ {\n", key, config.CONFIG_TREE_SEPARATOR)
+			fmt.Fprintf(b, "\t\titem := %s{}\n", field.nested.name)
+			fmt.Fprintf(b, "\t\tif err = item.decode(ctx, store, %s+%q+strconv.Itoa(i)+%q); err != nil {\n\t\t\treturn err\n\t\t}\n",
+				key, config.CONFIG_TREE_SEPARATOR, config.CONFIG_TREE_SEPARATOR)
+			fmt.Fprintf(b, "\t\tv.%s = append(v.%s, item)\n\t}\n", field.name, field.name)
+			continue
+		default:
+			getter := "config.Get"
+			if field.kind == KIND_SLICE {
+				getter = "config.GetSlice"
+			}
+			decode = fmt.Sprintf("if v.%s, err = %s[%s](ctx, store, %s); err != nil {\n\t\treturn err\n\t}\n",
+				field.name, getter, field.typeName, key)
+		}
+		// optional nested structs are only decoded if they are set, so their required keys do not fail
+		if field.required {
+			b.WriteString("\t" + decode)
+		} else {
+			fmt.Fprintf(b, "\tif store.Has(ctx, %s) {\n\t%s\t}\n", key, decode)
+		}
+	}
+	b.WriteString("\treturn nil\n}\n")
+}
+
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func quoteList(values []string) string {
+	if len(values) == 0 {
+		return "[]string(nil)"
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, strconv.Quote(value))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
@@ -0,0 +1,134 @@
+// gotils-configgen generates a typed Go struct for a config, with a Load<Type> function
+// reading it through config.NewLoadedConfig. Renamed or removed keys then break the
+// build of code using the struct instead of failing at runtime.
+//
+// The struct is derived from a sample config file (any format the config loader reads)
+// or from a JSON schema:
+//
+//	//go:generate go run github.com/myLogic207/gotils/cmd/gotils-configgen -sample config.yaml -env APP -out config_gen.go
+//	//go:generate go run github.com/myLogic207/gotils/cmd/gotils-configgen -schema schema.json -file config.yaml -out config_gen.go
+//
+// Types of sample values are inferred (bool, int, float64, time.Duration or string) and all
+// sample keys are required. With a schema only keys listed as required are required.
+// LoadConfig (named after -type) reads the environment prefixes given with -env and the files
+// given with -file, which default to the sample file. Paths are embedded as given, so relative
+// paths are resolved against the working directory of the program calling LoadConfig, not against
+// the generated file. Pass -file with the path the program runs with, or an absolute path.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/myLogic207/gotils/config"
+	_ "github.com/myLogic207/gotils/config/formats"
+	"github.com/myLogic207/gotils/config/schema"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// listFlag collects the values of a repeatable flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// run generates the code and returns the exit code, 2 for invalid usage
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gotils-configgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	sample := flags.String("sample", "", "sample config `file` to derive the struct from")
+	schemaPath := flags.String("schema", "", "JSON schema `file` to derive the struct from")
+	typeName := flags.String("type", "Config", "`name` of the generated struct")
+	packageName := flags.String("package", os.Getenv("GOPACKAGE"), "package `name` of the generated file, defaults to $GOPACKAGE")
+	output := flags.String("out", "", "output `file`, defaults to stdout")
+	profile := flags.String("profile", "", "profile `name` applied while reading the sample")
+	envPrefixes := listFlag{}
+	flags.Var(&envPrefixes, "env", "environment variable `prefix` read by Load<Type>, repeatable")
+	files := listFlag{}
+	flags.Var(&files, "file", "config `path` read by Load<Type>, relative paths are resolved at runtime, repeatable, defaults to the sample")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if (*sample == "") == (*schemaPath == "") || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "expected either -sample or -schema")
+		flags.Usage()
+		return 2
+	}
+	if *packageName == "" {
+		*packageName = "main"
+	}
+	if !token.IsIdentifier(*typeName) || !token.IsExported(*typeName) {
+		fmt.Fprintf(stderr, "type name %q is not an exported identifier\n", *typeName)
+		return 2
+	}
+
+	root, source, err := load(ctx, *sample, *schemaPath, *typeName, *profile)
+	if err != nil {
+		fmt.Fprintln(stderr, "gotils-configgen:", err)
+		return 1
+	}
+	if len(files) == 0 && *sample != "" {
+		files = listFlag{*sample}
+	}
+	gen := &generator{packageName: *packageName, source: source, envPrefixes: envPrefixes, files: files}
+	code, err := gen.generate(root)
+	if err != nil {
+		fmt.Fprintln(stderr, "gotils-configgen:", err)
+		return 1
+	}
+	if *output == "" {
+		_, err = stdout.Write(code)
+	} else {
+		err = os.WriteFile(*output, code, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "gotils-configgen:", err)
+		return 1
+	}
+	return 0
+}
+
+// load derives the struct from the sample or schema, returns it with the name of its source
+func load(ctx context.Context, sample string, schemaPath string, typeName string, profile string) (*goStruct, string, error) {
+	if schemaPath != "" {
+		file, err := os.Open(schemaPath)
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+		parsed, err := schema.Load(file)
+		if err != nil {
+			return nil, "", err
+		}
+		if !isObjectSchema(parsed) {
+			return nil, "", errors.New("schema does not describe an object")
+		}
+		root, err := fromSchema(parsed, typeName)
+		return root, schemaPath, err
+	}
+	opts := []config.Option{}
+	if profile != "" {
+		opts = append(opts, config.WithProfile(profile))
+	}
+	cfg, err := config.NewLoadedConfig(ctx, nil, []string{sample}, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	root, err := fromSample(ctx, cfg, "", typeName)
+	return root, sample, err
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSample = `name: app
+server:
+  host: localhost
+  port: 8080
+  timeout: 5s
+  tls_enabled: true
+hosts:
+  - alpha
+  - beta
+users:
+  - name: admin
+    id: 1
+  - name: guest
+`
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "description": "name of the service"},
+		"port": {"type": "integer"},
+		"ratio": {"type": "number"},
+		"server": {"type": "object", "required": ["debug"], "properties": {"debug": {"type": "boolean"}}},
+		"hosts": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+func generateCode(t *testing.T, args ...string) string {
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	if code := run(context.TODO(), args, stdout, stderr); code != 0 {
+		t.Fatalf("Generation failed with %d: %s", code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestGenerateFromSample(t *testing.T) {
+	sample := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(sample, []byte(testSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code := generateCode(t, "-sample", sample, "-package", "demo", "-type", "AppConfig", "-env", "APP")
+	for _, expected := range []string{
+		"package demo",
+		"Hosts  []string         `config:\"HOSTS\"`",
+		"Server AppConfigServer  `config:\"SERVER\"`",
+		"Users  []AppConfigUsers `config:\"USERS\"`",
+		"Timeout    time.Duration `config:\"TIMEOUT\"`",
+		"TLSEnabled bool          `config:\"TLS_ENABLED\"`",
+		"appConfigEnvPrefixes = []string{\"APP\"}",
+		"func LoadAppConfig(ctx context.Context, opts ...config.Option) (*AppConfig, error)",
+		"func DecodeAppConfig(ctx context.Context, store config.ConfigStore) (*AppConfig, error)",
+		// keys are read from the tags
+		"key := func(i int) string { return prefix + fields.Field(i).Tag.Get(\"config\") }",
+		// id is missing in the second user, so it is optional
+		"func (v *AppConfigUsers) decode(ctx context.Context, store config.ConfigStore, prefix string) error {\n\tfields := reflect.TypeOf(*v)\n\tkey := func(i int) string { return prefix + fields.Field(i).Tag.Get(\"config\") }\n\tvar err error\n\tif store.Has(ctx, key(0)) {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateFromSchema(t *testing.T) {
+	schema := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schema, []byte(testSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code := generateCode(t, "-schema", schema, "-package", "demo", "-file", "app.env")
+	for _, expected := range []string{
+		"// name of the service\n\tName   string ",
+		"Port   int ",
+		"Ratio  float64 ",
+		"Hosts []string ",
+		"Debug bool `config:\"DEBUG\"`",
+		"configFiles       = []string{\"app.env\"}",
+		"\tif v.Name, err = config.Get[string](ctx, store, key(1)); err != nil {",
+		"\tif store.Has(ctx, key(2)) {",
+		// server is optional, so its required debug key is only read if server is set
+		"\tif store.Has(ctx, key(4)) {\n\t\tif err = v.Server.decode(ctx, store, key(4)+\"/\"); err != nil {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-sample", "a.env", "-schema", "b.json"},
+		{"-sample", "a.env", "-type", "lower"},
+	} {
+		if code := run(context.TODO(), args, &strings.Builder{}, &strings.Builder{}); code != 2 {
+			t.Errorf("%v: expected usage error, got %d", args, code)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	names := map[string]string{
+		"LOG_LEVEL":  "LogLevel",
+		"db-host":    "DBHost",
+		"API_URL":    "APIURL",
+		"0":          "X0",
+		"PORT":       "Port",
+		"max_ttl_ms": "MaxTTLMs",
+	}
+	for segment, expected := range names {
+		if name := goName(segment); name != expected {
+			t.Errorf("%s: expected %s, got %s", segment, expected, name)
+		}
+	}
+}
+
+// TestGeneratedCodeRuns builds the generated code with a small main and checks the decoded values
+func TestGeneratedCodeRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+	// the package has to be inside the module to import the config package
+	dir, err := os.MkdirTemp(".", "_testgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sample, err := filepath.Abs(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sample, []byte(testSample), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code := generateCode(t, "-sample", sample, "-package", "main", "-env", "GENTEST")
+	// a second struct generated into the same package
+	other := generateCode(t, "-sample", sample, "-package", "main", "-type", "OtherConfig")
+	program := `package main
+
+import (
+	"context"
+	"fmt"
+)
+
+var _ = LoadOtherConfig
+
+func main() {
+	cfg, err := LoadConfig(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(cfg.Name, cfg.Server.Port, cfg.Server.Timeout, cfg.Server.TLSEnabled, cfg.Hosts, len(cfg.Users), cfg.Users[0].ID)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "config_gen.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other_gen.go"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goTool, "run", "./"+filepath.Base(dir))
+	cmd.Env = append(os.Environ(), "GENTEST_SERVER_PORT=9090")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Generated code failed: %v\n%s", err, output)
+	}
+	if expected := "app 9090 5s true [alpha beta] 2 1\n"; string(output) != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// DeprecationHandler is called once per config and deprecated key when the key is read or loaded.
+// replacement is empty if the key has no replacement.
+type DeprecationHandler func(key string, replacement string)
+
+// logDeprecation is the handler of configs created without WithDeprecationHandler, it logs a warning
+func logDeprecation(key string, replacement string) {
+	if replacement == "" {
+		log.Printf("config key %s is deprecated", key)
+		return
+	}
+	log.Printf("config key %s is deprecated, use %s instead", key, replacement)
+}
+
+// keyAliases maps alternative key names to their canonical key, it applies to subtrees as well
+type keyAliases struct {
+	mu         sync.RWMutex
+	aliases    map[string]string
+	deprecated map[string]string
+	warned     map[string]bool
+	handler    DeprecationHandler
+	// frozen registries are not changed anymore, so they are read without locking
+	frozen bool
+}
+
+func newKeyAliases() *keyAliases {
+	return &keyAliases{
+		aliases:    make(map[string]string),
+		deprecated: make(map[string]string),
+		warned:     make(map[string]bool),
+	}
+}
+
+// Alias registers aliases for key, reading or writing an alias accesses key instead.
+// Aliases of a parent key apply to all keys below it. Register aliases before sharing the config.
+func (c *Config) Alias(key string, aliases ...string) error {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	registry := c.keyAliases()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	key, _ = registry.resolve(key)
+	for _, alias := range aliases {
+		alias = strings.ToUpper(strings.TrimSpace(alias))
+		if err := IsValidKey(alias); err != nil { // check key is valid
+			return err
+		}
+		if existing, ok := registry.aliases[alias]; alias == key || ok && existing != key {
+			return &ErrAliasConflict{alias: alias, key: key}
+		}
+		registry.aliases[alias] = key
+	}
+	return nil
+}
+
+// Deprecate marks key as deprecated, if replacement is given key becomes an alias of it.
+// Reading or loading a deprecated key calls the deprecation handler once.
+func (c *Config) Deprecate(key string, replacement string) error {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	replacement = strings.ToUpper(strings.TrimSpace(replacement))
+	if replacement != "" {
+		if err := c.Alias(replacement, key); err != nil {
+			return err
+		}
+	}
+	registry := c.keyAliases()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.deprecated[key] = replacement
+	return nil
+}
+
+func (c *Config) keyAliases() *keyAliases {
+	if c.aliases == nil {
+		c.aliases = newKeyAliases()
+	}
+	return c.aliases
+}
+
+// resolveKey returns the canonical key for key and warns if a deprecated key is used
+func (c *Config) resolveKey(key string) string {
+	if c.aliases == nil {
+		return key
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(key))
+	resolved, deprecated := c.aliases.lookup(normalized)
+	if deprecated != "" {
+		c.aliases.warn(deprecated)
+	}
+	if resolved == normalized {
+		// keep the key as given, so errors report it unchanged
+		return key
+	}
+	return resolved
+}
+
+// lookup resolves key, taking the lock unless the registry is frozen
+func (a *keyAliases) lookup(key string) (string, string) {
+	if !a.frozen {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+	}
+	return a.resolve(key)
+}
+
+// resolve maps key to its canonical key, the lock has to be held.
+// Returns the deprecated key or parent that was used, or an empty string.
+func (a *keyAliases) resolve(key string) (string, string) {
+	deprecated := ""
+	if _, ok := a.deprecated[key]; ok {
+		deprecated = key
+	}
+	if canonical, ok := a.aliases[key]; ok {
+		return canonical, deprecated
+	}
+	// check parent keys, the longest alias wins
+	for i := strings.LastIndex(key, CONFIG_TREE_SEPARATOR); i > 0; i = strings.LastIndex(key[:i], CONFIG_TREE_SEPARATOR) {
+		parent := key[:i]
+		if _, ok := a.deprecated[parent]; ok && deprecated == "" {
+			deprecated = parent
+		}
+		if canonical, ok := a.aliases[parent]; ok {
+			return canonical + key[i:], deprecated
+		}
+	}
+	return key, deprecated
+}
+
+func (a *keyAliases) warn(key string) {
+	a.mu.Lock()
+	if a.warned[key] {
+		a.mu.Unlock()
+		return
+	}
+	a.warned[key] = true
+	handler := a.handler
+	replacement := a.deprecated[key]
+	a.mu.Unlock()
+	if handler == nil {
+		handler = logDeprecation
+	}
+	handler(key, replacement)
+}
+
+// below copies the aliases within the subtree at prefix relative to it, for the config of the subtree.
+// Aliases pointing out of the subtree can not be resolved there and are left out.
+func (a *keyAliases) below(prefix string) *keyAliases {
+	if a == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	below := newKeyAliases()
+	below.handler = a.handler
+	for alias, key := range a.aliases {
+		relativeAlias, aliasOk := keytree.CutPrefix(alias, prefix)
+		relativeKey, keyOk := keytree.CutPrefix(key, prefix)
+		if aliasOk && keyOk && relativeAlias != "" && relativeKey != "" {
+			below.aliases[relativeAlias] = relativeKey
+		}
+	}
+	for key, replacement := range a.deprecated {
+		relativeKey, ok := keytree.CutPrefix(key, prefix)
+		if !ok || relativeKey == "" {
+			continue
+		}
+		if relativeReplacement, ok := keytree.CutPrefix(replacement, prefix); ok && relativeReplacement != "" {
+			replacement = relativeReplacement
+		}
+		below.deprecated[relativeKey] = replacement
+	}
+	return below
+}
+
+// clone copies the registered aliases, warnings are issued again for the copy
+func (a *keyAliases) clone() *keyAliases {
+	if a == nil {
+		return nil
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	clone := newKeyAliases()
+	clone.handler = a.handler
+	for alias, key := range a.aliases {
+		clone.aliases[alias] = key
+	}
+	for key, replacement := range a.deprecated {
+		clone.deprecated[key] = replacement
+	}
+	return clone
+}
+
+// aliasTx resolves aliases for keys used in a transaction
+type aliasTx struct {
+	tx     Tx
+	config *Config
+}
+
+func (t *aliasTx) Get(ctx context.Context, key string) (string, error) {
+	return t.tx.Get(ctx, t.config.resolveKey(key))
+}
+
+func (t *aliasTx) Has(ctx context.Context, key string) bool {
+	return t.tx.Has(ctx, t.config.resolveKey(key))
+}
+
+func (t *aliasTx) Set(ctx context.Context, key string, value string) error {
+	return t.tx.Set(ctx, t.config.resolveKey(key), value)
+}
+
+func (t *aliasTx) SetTyped(ctx context.Context, key string, value any) error {
+	return setTx(ctx, t.tx, t.config.resolveKey(key), value)
+}
+
+func (t *aliasTx) Delete(ctx context.Context, key string) error {
+	return t.tx.Delete(ctx, t.config.resolveKey(key))
+}
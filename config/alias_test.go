@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"columnlength": "16",
+		"writers":      map[string]interface{}{"file": map[string]interface{}{"active": "true"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("COLUMNLENGTH", "COLUMLENGTH", "width"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("WRITERS/FILE", "LOGFILE"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"COLUMNLENGTH", "columlength", "WIDTH"} {
+		if value, err := config.Get(ctx, key); err != nil || value != "16" {
+			t.Errorf("Expected '16' for %s, got '%s' (%v)", key, value, err)
+		}
+	}
+	if value, err := config.Get(ctx, "LOGFILE/ACTIVE"); err != nil || value != "true" {
+		t.Errorf("Expected alias of parent to resolve, got '%s' (%v)", value, err)
+	}
+	// writes through an alias go to the canonical key
+	if err := config.Set(ctx, "WIDTH", "8", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get[int](ctx, config, "COLUMNLENGTH"); err != nil || value != 8 {
+		t.Errorf("Expected 8, got %d (%v)", value, err)
+	}
+	if err := config.Update(ctx, func(tx Tx) error { return tx.Set(ctx, "LOGFILE/FOLDER", "/tmp") }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.Keys(ctx), []string{"COLUMNLENGTH", "WRITERS/FILE/ACTIVE", "WRITERS/FILE/FOLDER"}) {
+		t.Errorf("Unexpected keys %v", config.Keys(ctx))
+	}
+	if err := config.Alias("OTHER", "WIDTH"); !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected alias conflict, got %v", err)
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	ctx := context.TODO()
+	warnings := map[string]string{}
+	calls := 0
+	config, err := New(ctx, WithDeprecationHandler(func(key string, replacement string) {
+		calls++
+		warnings[key] = replacement
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Deprecate("COLUMLENGTH", "COLUMNLENGTH"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Deprecate("LEGACY", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "COLUMNLENGTH", "16", true); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Error("Canonical key triggered a deprecation warning")
+	}
+	for i := 0; i < 3; i++ {
+		if value, err := config.Get(ctx, "COLUMLENGTH"); err != nil || value != "16" {
+			t.Errorf("Expected '16', got '%s' (%v)", value, err)
+		}
+	}
+	config.Has(ctx, "LEGACY/NESTED")
+	if calls != 2 || warnings["COLUMLENGTH"] != "COLUMNLENGTH" || warnings["LEGACY"] != "" {
+		t.Errorf("Expected one warning per key, got %d calls %v", calls, warnings)
+	}
+}
+
+func TestDeprecatedKeyLoaded(t *testing.T) {
+	ctx := context.TODO()
+	warned := []string{}
+	config, err := New(ctx, WithDeprecationHandler(func(key string, replacement string) {
+		warned = append(warned, key)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Deprecate("OLDNAME", "NEWNAME"); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"app.conf": "OLDNAME=value\n"})
+	if err := config.Load(ctx, []string{"ALIASTEST"}, []string{path.Join(dir, "app.conf")}); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "NEWNAME"); err != nil || value != "value" {
+		t.Errorf("Expected loaded value under new name, got '%s' (%v)", value, err)
+	}
+	if len(warned) != 1 || warned[0] != "OLDNAME" {
+		t.Errorf("Expected a warning for the loaded key, got %v", warned)
+	}
+}
+
+func TestAliasConcurrentRead(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"width": "16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registering the first alias while the config is read must not race
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			config.Get(ctx, "WIDTH")
+		}
+	}()
+	if err := config.Alias("WIDTH", "COLUMNS"); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+}
+
+func TestGetConfigAliasesAndHooks(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"writers": map[string]interface{}{"file": map[string]interface{}{"folder": "logs"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("WRITERS/FILE/FOLDER", "WRITERS/FILE/DIR"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("WRITERS/*/FOLDER", TrimValue); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := config.GetConfig(ctx, "writers/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := sub.Get(ctx, "DIR"); err != nil || value != "logs" {
+		t.Errorf("Expected alias in subtree, got '%s' (%v)", value, err)
+	}
+	if err := sub.Set(ctx, "DIR", " /var/log ", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := sub.Get(ctx, "FOLDER"); err != nil || value != "/var/log" {
+		t.Errorf("Expected hook to run in subtree, got '%s' (%v)", value, err)
+	}
+	if err := sub.Set(ctx, "PREFIX", " app ", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := sub.Get(ctx, "PREFIX"); value != " app " {
+		t.Errorf("Expected hook not to match other keys, got '%s'", value)
+	}
+}
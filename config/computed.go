@@ -0,0 +1,298 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ComputeFunc returns the value of a computed key, config can be used to derive it from other keys
+type ComputeFunc func(ctx context.Context, config *Config) (string, error)
+
+// ComputedOption configures a computed key
+type ComputedOption func(*computedKey)
+
+// Memoize keeps the first computed value instead of computing it on every read
+func Memoize() ComputedOption {
+	return func(k *computedKey) {
+		k.memoize = true
+	}
+}
+
+// DependsOn memoizes the value until the values of keys, or of keys below them, change
+func DependsOn(keys ...string) ComputedOption {
+	return func(k *computedKey) {
+		k.memoize = true
+		for _, key := range keys {
+			k.dependencies = append(k.dependencies, strings.ToUpper(strings.TrimSpace(key)))
+		}
+	}
+}
+
+// computedKeys holds the keys registered with Compute
+type computedKeys struct {
+	mu   sync.RWMutex
+	keys map[string]*computedKey
+	// frozen registries are not changed anymore, so they are read without locking
+	frozen bool
+}
+
+type computedKey struct {
+	fn           ComputeFunc
+	memoize      bool
+	dependencies []string
+
+	mu     sync.Mutex
+	cached bool
+	value  string
+	// state holds the dependency values the cached value was computed from
+	state string
+}
+
+func newComputedKeys() *computedKeys {
+	return &computedKeys{keys: make(map[string]*computedKey)}
+}
+
+// Compute registers a key whose value is returned by fn when it is read.
+// Computed keys are listed by Keys and included in dumps, walks and comparisons like stored keys,
+// their value takes precedence over a stored value of the same key and setting them returns ErrKeyComputed.
+// Register keys before sharing the config.
+func (c *Config) Compute(key string, fn ComputeFunc, opts ...ComputedOption) error {
+	key = strings.ToUpper(strings.TrimSpace(c.resolveKey(key)))
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+	computed := &computedKey{fn: fn}
+	for _, opt := range opts {
+		opt(computed)
+	}
+	for _, dependency := range computed.dependencies {
+		if err := IsValidKey(dependency); err != nil {
+			return err
+		}
+	}
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	if c.computed == nil {
+		c.computed = newComputedKeys()
+	}
+	c.computed.mu.Lock()
+	defer c.computed.mu.Unlock()
+	c.computed.keys[key] = computed
+	return nil
+}
+
+// Invalidate drops the memoized values of key and all computed keys below it
+func (c *Config) Invalidate(key string) {
+	key = strings.ToUpper(strings.TrimSpace(c.resolveKey(key)))
+	for _, computed := range c.computedBelow(key) {
+		computed.mu.Lock()
+		computed.cached = false
+		computed.mu.Unlock()
+	}
+}
+
+// checkComputed rejects writes to computed keys
+func (c *Config) checkComputed(key string) error {
+	if c.computed == nil {
+		return nil
+	}
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if _, ok := c.computed.get(key); ok {
+		return &ErrKeyComputed{key: key}
+	}
+	return nil
+}
+
+// readError keeps errors of computed keys, any other read error means the key is missing
+func readError(key string, err error) error {
+	var computeErr *ErrComputedValue
+	var cycleErr *ErrComputeCycle
+	if errors.As(err, &computeErr) || errors.As(err, &cycleErr) {
+		return err
+	}
+	return &ErrKeyNotFound{key: key}
+}
+
+// computedBelow returns the computed keys at or below key
+func (c *Config) computedBelow(key string) map[string]*computedKey {
+	if c.computed == nil {
+		return nil
+	}
+	if !c.computed.frozen {
+		c.computed.mu.RLock()
+		defer c.computed.mu.RUnlock()
+	}
+	matches := make(map[string]*computedKey)
+	for computedKey, computed := range c.computed.keys {
+		if matchesKey(computedKey, key) {
+			matches[computedKey] = computed
+		}
+	}
+	return matches
+}
+
+// computedValue returns the value of key if it is a computed key
+func (c *Config) computedValue(ctx context.Context, key string) (string, bool, error) {
+	if c.computed == nil {
+		return "", false, nil
+	}
+	key = strings.ToUpper(strings.TrimSpace(key))
+	computed, ok := c.computed.get(key)
+	if !ok {
+		return "", false, nil
+	}
+	value, err := c.compute(ctx, key, computed)
+	return value, true, err
+}
+
+type computeChainKey struct{}
+
+// compute evaluates a computed key, the lock is not held while fn runs,
+// so computed keys can read each other
+func (c *Config) compute(ctx context.Context, key string, computed *computedKey) (string, error) {
+	chain, _ := ctx.Value(computeChainKey{}).([]string)
+	if slices.Contains(chain, key) {
+		return "", &ErrComputeCycle{chain: append(slices.Clone(chain), key)}
+	}
+	ctx = context.WithValue(ctx, computeChainKey{}, append(slices.Clone(chain), key))
+
+	state := ""
+	if computed.memoize {
+		state = c.dependencyState(ctx, computed.dependencies)
+		computed.mu.Lock()
+		if computed.cached && computed.state == state {
+			value := computed.value
+			computed.mu.Unlock()
+			return value, nil
+		}
+		computed.mu.Unlock()
+	}
+	value, err := computed.fn(ctx, c)
+	if err != nil {
+		return "", &ErrComputedValue{key: key, nested: err}
+	}
+	if computed.memoize {
+		computed.mu.Lock()
+		computed.cached, computed.value, computed.state = true, value, state
+		computed.mu.Unlock()
+	}
+	return value, nil
+}
+
+// dependencyState describes the current values of the dependencies
+func (c *Config) dependencyState(ctx context.Context, dependencies []string) string {
+	state := strings.Builder{}
+	for _, dependency := range dependencies {
+		values := c.GetAll(ctx, dependency)
+		suffixes := make([]string, 0, len(values))
+		for suffix := range values {
+			suffixes = append(suffixes, suffix)
+		}
+		slices.Sort(suffixes)
+		state.WriteString(dependency + "\x00")
+		for _, suffix := range suffixes {
+			state.WriteString(suffix + "=" + values[suffix] + "\x00")
+		}
+	}
+	return state.String()
+}
+
+// computedValues returns the values of the computed keys at or below key indexed like GetAll,
+// keys that fail to compute are left out and their errors returned joined
+func (c *Config) computedValues(ctx context.Context, key string) (map[string]string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	values := make(map[string]string)
+	errs := []error{}
+	for computedKey, computed := range c.computedBelow(key) {
+		value, err := c.compute(ctx, computedKey, computed)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		suffix := strings.TrimPrefix(strings.TrimPrefix(computedKey, key), CONFIG_TREE_SEPARATOR)
+		values[suffix] = value
+	}
+	return values, errors.Join(errs...)
+}
+
+// computedKeyList returns the computed keys without evaluating them
+func (c *Config) computedKeyList() []string {
+	if c.computed == nil {
+		return nil
+	}
+	if !c.computed.frozen {
+		c.computed.mu.RLock()
+		defer c.computed.mu.RUnlock()
+	}
+	keys := make([]string, 0, len(c.computed.keys))
+	for key := range c.computed.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// get returns the computed key, taking the lock unless the registry is frozen
+func (k *computedKeys) get(key string) (*computedKey, bool) {
+	if !k.frozen {
+		k.mu.RLock()
+		defer k.mu.RUnlock()
+	}
+	computed, ok := k.keys[key]
+	return computed, ok
+}
+
+// clone copies the registered keys, memoized values are computed again for the copy
+func (k *computedKeys) clone() *computedKeys {
+	if k == nil {
+		return nil
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	clone := newComputedKeys()
+	for key, computed := range k.keys {
+		clone.keys[key] = &computedKey{fn: computed.fn, memoize: computed.memoize, dependencies: computed.dependencies}
+	}
+	return clone
+}
+
+// computedTx reads computed keys and rejects writes to them in a transaction
+type computedTx struct {
+	tx     Tx
+	config *Config
+}
+
+func (t *computedTx) Get(ctx context.Context, key string) (string, error) {
+	if value, ok, err := t.config.computedValue(ctx, key); ok {
+		return value, err
+	}
+	return t.tx.Get(ctx, key)
+}
+
+func (t *computedTx) Has(ctx context.Context, key string) bool {
+	if len(t.config.computedBelow(strings.ToUpper(strings.TrimSpace(key)))) > 0 {
+		return true
+	}
+	return t.tx.Has(ctx, key)
+}
+
+func (t *computedTx) Set(ctx context.Context, key string, value string) error {
+	if err := t.config.checkComputed(key); err != nil {
+		return err
+	}
+	return t.tx.Set(ctx, key, value)
+}
+
+func (t *computedTx) SetTyped(ctx context.Context, key string, value any) error {
+	if err := t.config.checkComputed(key); err != nil {
+		return err
+	}
+	return setTx(ctx, t.tx, key, value)
+}
+
+func (t *computedTx) Delete(ctx context.Context, key string) error {
+	return t.tx.Delete(ctx, key)
+}
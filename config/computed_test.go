@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	if err := config.Compute("hostname", func(ctx context.Context, config *Config) (string, error) {
+		calls++
+		return "node-" + strconv.Itoa(calls), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Compute("DB/URL", func(ctx context.Context, config *Config) (string, error) {
+		host, err := config.Get(ctx, "DB/HOST")
+		if err != nil {
+			return "", err
+		}
+		port, err := config.Get(ctx, "DB/PORT")
+		return host + ":" + port, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// values are computed on every read
+	if value, err := config.Get(ctx, "HOSTNAME"); err != nil || value != "node-1" {
+		t.Errorf("Unexpected value '%s' (%v)", value, err)
+	}
+	if value, _ := config.Get(ctx, "HOSTNAME"); value != "node-2" {
+		t.Errorf("Expected value to be computed again, got '%s'", value)
+	}
+	if port, err := Get[string](ctx, config, "db/url"); err != nil || port != "localhost:5432" {
+		t.Errorf("Unexpected typed value '%s' (%v)", port, err)
+	}
+
+	// computed keys behave like stored keys
+	if keys := config.Keys(ctx); !slices.Equal(keys, []string{"DB/HOST", "DB/PORT", "DB/URL", "HOSTNAME"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if !config.Has(ctx, "DB/URL") || config.GetAll(ctx, "DB")["URL"] != "localhost:5432" {
+		t.Errorf("Expected computed key below DB, got %v", config.GetAll(ctx, "DB"))
+	}
+	if err := config.CompareMap(ctx, map[string]string{"DB/URL": "localhost:5432"}, true); err != nil {
+		t.Error(err)
+	}
+	other, err := WithInitialValues(ctx, map[string]interface{}{"db": map[string]interface{}{"url": "localhost:5432"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Compare(ctx, other, true); err != nil {
+		t.Error(err)
+	}
+	buffer := &bytes.Buffer{}
+	if err := config.Dump(ctx, buffer, FORMAT_ENV); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buffer.Bytes(), []byte("DB/URL=localhost:5432\n")) {
+		t.Errorf("Expected computed key in dump, got:\n%s", buffer.String())
+	}
+
+	// copies keep computing the value
+	copied, err := config.Copy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copied.Set(ctx, "DB/HOST", "remote", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := copied.Get(ctx, "DB/URL"); value != "remote:5432" {
+		t.Errorf("Expected copy to compute from its own values, got '%s'", value)
+	}
+}
+
+func TestComputeMemoize(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"name": "app", "db": map[string]interface{}{"host": "localhost"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	count := func(ctx context.Context, config *Config) (string, error) {
+		calls++
+		name, err := config.Get(ctx, "NAME")
+		return name + "-" + strconv.Itoa(calls), err
+	}
+	if err := config.Compute("LAZY", count, Memoize()); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Compute("DERIVED", count, DependsOn("NAME", "DB")); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if value, _ := config.Get(ctx, "LAZY"); value != "app-1" {
+			t.Fatalf("Expected memoized value, got '%s'", value)
+		}
+		if value, _ := config.Get(ctx, "DERIVED"); value != "app-2" {
+			t.Fatalf("Expected memoized value, got '%s'", value)
+		}
+	}
+
+	// changing a dependency, or a key below it, computes the value again
+	if err := config.Set(ctx, "NAME", "other", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := config.Get(ctx, "DERIVED"); value != "other-3" {
+		t.Errorf("Expected value to be recomputed, got '%s'", value)
+	}
+	if err := config.Set(ctx, "DB/PORT", "5432", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := config.Get(ctx, "DERIVED"); value != "other-4" {
+		t.Errorf("Expected value to be recomputed, got '%s'", value)
+	}
+	if value, _ := config.Get(ctx, "LAZY"); value != "app-1" {
+		t.Errorf("Expected memoized value without dependencies, got '%s'", value)
+	}
+	config.Invalidate("LAZY")
+	if value, _ := config.Get(ctx, "LAZY"); value != "other-5" {
+		t.Errorf("Expected invalidated value to be recomputed, got '%s'", value)
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errLookup := errors.New("lookup failed")
+	if err := config.Compute("BROKEN", func(ctx context.Context, config *Config) (string, error) {
+		return "", errLookup
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"A", "B"} {
+		other := map[string]string{"A": "B", "B": "A"}[key]
+		if err := config.Compute(key, func(ctx context.Context, config *Config) (string, error) {
+			return config.Get(ctx, other)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := config.Get(ctx, "BROKEN"); !errors.Is(err, errLookup) {
+		t.Errorf("Expected compute error, got %v", err)
+	}
+	var cycleErr *ErrComputeCycle
+	if _, err := config.Get(ctx, "A"); !errors.As(err, &cycleErr) {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+	// failing keys are listed, but left out of reads that can not report errors
+	if len(config.Keys(ctx)) != 3 || len(config.GetAll(ctx, "BROKEN")) != 0 {
+		t.Errorf("Unexpected keys %v", config.Keys(ctx))
+	}
+	if err := config.Dump(ctx, io.Discard, FORMAT_ENV); !errors.Is(err, errLookup) {
+		t.Errorf("Expected dump to return the compute error, got %v", err)
+	}
+	copied, err := config.Copy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var computeErr *ErrComputedValue
+	if err := config.Compare(ctx, copied, true); !errors.As(err, &computeErr) && !errors.As(err, &cycleErr) {
+		t.Errorf("Expected compare to return the compute error, got %v", err)
+	}
+	if err := config.Compute("INVALID KEY", nil); err == nil {
+		t.Error("Expected invalid key to be rejected")
+	}
+}
+
+func TestComputeReadOnly(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"HOST": "db", "URL": "stale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Compute("URL", func(ctx context.Context, config *Config) (string, error) {
+		host, err := config.Get(ctx, "HOST")
+		return "postgres://" + host, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var computedErr *ErrKeyComputed
+	if err := config.Set(ctx, "URL", "other", true); !errors.As(err, &computedErr) || !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected computed key error, got %v", err)
+	}
+	if err := Set(ctx, config, "url", 1, true); !errors.As(err, &computedErr) {
+		t.Errorf("Expected computed key error for typed set, got %v", err)
+	}
+	if err := config.Update(ctx, func(tx Tx) error {
+		if value, err := tx.Get(ctx, "URL"); err != nil || value != "postgres://db" {
+			t.Errorf("Expected computed value in transaction, got '%s' (%v)", value, err)
+		}
+		return tx.Set(ctx, "URL", "other")
+	}); !errors.As(err, &computedErr) {
+		t.Errorf("Expected computed key error in transaction, got %v", err)
+	}
+	if value, err := config.Get(ctx, "URL"); err != nil || value != "postgres://db" {
+		t.Errorf("Expected 'postgres://db', got '%s' (%v)", value, err)
+	}
+}
@@ -2,108 +2,110 @@ package config
 
 import (
 	"context"
-	"errors"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 
+	"github.com/myLogic207/gotils/config/internal/keytree"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	CONFIG_TREE_SEPARATOR = "/"
+	CONFIG_TREE_SEPARATOR = keytree.SEPARATOR
 	KEY_ALLOWED_CHARS     = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-"
 )
 
 type Config struct {
-	loader Loader
+	loader  Loader
+	profile string
+	// frozen configs reject all writes, see Freeze
+	frozen bool
+	// aliases holds alternative and deprecated key names, see Alias
+	aliases *keyAliases
+	// setHooks validate and normalize written values, see OnSet
+	setHooks *setHooks
+	// computed holds keys whose values are computed on read, see Compute
+	computed *computedKeys
+	// newStore creates the stores of derived configs, see WithStore
+	newStore ConfigStoreNew
 	ConfigStore
 }
 
-func New(ctx context.Context) (*Config, error) {
-	store, err := DefaultConfigStore(ctx)
+// New creates an empty config, options can set the store and the loader
+func New(ctx context.Context, opts ...Option) (*Config, error) {
+	options := newOptions(opts)
+	newStore := options.newStore
+	if newStore == nil {
+		newStore = DefaultConfigStore
+	}
+	store, err := newStore(ctx)
 	if err != nil {
 		return nil, err
 	}
+	loader := options.loader
+	if loader == nil {
+		loader = &ConfigLoader{}
+	}
+	// the registries are created here, so registering aliases, hooks and computed keys does not race with reads
+	aliases := newKeyAliases()
+	aliases.handler = options.deprecated
 	config := &Config{
-		loader:      &ConfigLoader{},
+		loader:      loader,
+		aliases:     aliases,
+		setHooks:    &setHooks{},
+		computed:    newComputedKeys(),
+		newStore:    newStore,
 		ConfigStore: store,
 	}
 	return config, nil
 }
 
-func WithInitialValues(ctx context.Context, initialValues map[string]interface{}) (*Config, error) {
-	store, err := DefaultConfigStore(ctx)
+// createStore creates an empty store like the one of the config
+func (c *Config) createStore(ctx context.Context) (ConfigStore, error) {
+	if c.newStore == nil {
+		return DefaultConfigStore(ctx)
+	}
+	return c.newStore(ctx)
+}
+
+// WithInitialValues creates a config from a nested value map, all values are set at once.
+// Values keep their type, so Get does not need to parse them again. A nil value is rejected with ErrKeyValueInvalid.
+func WithInitialValues(ctx context.Context, initialValues map[string]interface{}, opts ...Option) (*Config, error) {
+	config, err := New(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
-	errGroup, eCtx := errgroup.WithContext(ctx)
-	errGroup.Go(func() error {
-		defer func() {
-			if r := recover(); r != nil {
-				errGroup.Go(func() error {
-					return &ErrKeyValueInvalid{key: "", value: initialValues, nested: errors.New("panic during initial value set")}
-				})
-			}
-		}()
-		recursiveSet(eCtx, store, "", initialValues, errGroup)
+	typedValues := make(map[string]any)
+	if err := walkValues("", initialValues, func(key string, value any) error {
+		if value == nil {
+			return &ErrKeyValueInvalid{key: key, value: value}
+		}
+		if _, err := formatValue(key, value); err != nil {
+			return err
+		}
+		typedValues[key] = value
 		return nil
-	})
-	if err := errGroup.Wait(); err != nil {
+	}); err != nil {
 		return nil, err
 	}
-	config := &Config{
-		loader:      &ConfigLoader{},
-		ConfigStore: store,
-	}
-	return config, errGroup.Wait()
-}
-
-func recursiveSet(ctx context.Context, store ConfigStore, baseKey string, valueMap map[string]interface{}, errGroup *errgroup.Group) {
-	for key, val := range valueMap {
-		k := strings.Join([]string{baseKey, key}, CONFIG_TREE_SEPARATOR)
-		k = strings.TrimPrefix(k, CONFIG_TREE_SEPARATOR)
-		switch v := val.(type) {
-		case map[string]interface{}:
-			recursiveSet(ctx, store, k, v, errGroup)
-		case string:
-			errGroup.Go(func() error { return store.Set(ctx, k, v, true) })
-		case int:
-			errGroup.Go(func() error { return store.Set(ctx, k, strconv.Itoa(v), true) })
-		case bool:
-			errGroup.Go(func() error { return store.Set(ctx, k, strconv.FormatBool(v), true) })
-		case float64:
-			errGroup.Go(func() error { return store.Set(ctx, k, strconv.FormatFloat(v, 'f', -1, 64), true) })
-		default:
-			errGroup.Go(func() error { return &ErrKeyValueInvalid{key: k, value: v} })
+	if err := config.Update(ctx, func(tx Tx) error {
+		for key, value := range typedValues {
+			if err := setTx(ctx, tx, key, value); err != nil {
+				return err
+			}
 		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
+	return config, nil
 }
 
-func WithInitialValuesAndOptions(ctx context.Context, initialValues map[string]interface{}, options *Config) (*Config, error) {
-	store, err := DefaultConfigStore(ctx)
+func WithInitialValuesAndOptions(ctx context.Context, initialValues map[string]interface{}, options *Config, opts ...Option) (*Config, error) {
+	config, err := WithInitialValues(ctx, initialValues, opts...)
 	if err != nil {
 		return nil, err
 	}
-	errGroup, eCtx := errgroup.WithContext(ctx)
-	errGroup.Go(func() error {
-		defer func() {
-			if r := recover(); r != nil {
-				errGroup.Go(func() error {
-					return &ErrKeyValueInvalid{key: "", value: initialValues, nested: errors.New("panic during initial value set")}
-				})
-			}
-		}()
-		recursiveSet(eCtx, store, "", initialValues, errGroup)
-		return nil
-	})
-	if err := errGroup.Wait(); err != nil {
-		return nil, err
-	}
-	config := &Config{
-		loader:      &ConfigLoader{},
-		ConfigStore: store,
-	}
 
 	if err := config.Merge(ctx, options, true); err != nil {
 		return nil, err
@@ -112,25 +114,51 @@ func WithInitialValuesAndOptions(ctx context.Context, initialValues map[string]i
 	return config, nil
 }
 
-func NewLoadedConfig(ctx context.Context, envPrefixList []string, fileList []string) (*Config, error) {
-	config, err := New(ctx)
+// NewLoadedConfig creates a config from environment variables and files.
+// Options can select a profile, whose overlays are applied while loading.
+func NewLoadedConfig(ctx context.Context, envPrefixList []string, fileList []string, opts ...Option) (*Config, error) {
+	config, err := New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	options := newOptions(opts)
+	profile, loaded, err := resolveProfile(ctx, config, options, envPrefixList, fileList)
 	if err != nil {
 		return nil, err
 	}
+	config.profile = profile
+	if config.loader, err = options.loaderFor(profile); err != nil {
+		return nil, err
+	}
+	if loaded {
+		// without a profile the load that read the profile key is kept
+		return config, nil
+	}
 	if err := config.Load(ctx, envPrefixList, fileList); err != nil {
 		return nil, err
 	}
 	return config, nil
 }
 
+// Profile returns the profile that was active while loading the config
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// Load reads the environment and then the files, keys already set are kept,
+// so environment variables take precedence over file values
 func (c *Config) Load(ctx context.Context, envPrefixList []string, fileList []string) error {
 	if c.loader == nil {
 		return ErrNoConfigSource
 	}
-	errGroup, eCtx := errgroup.WithContext(ctx)
-	errGroup.Go(func() error { return c.loader.LoadEnv(eCtx, c.ConfigStore, envPrefixList) })
-	errGroup.Go(func() error { return c.loader.LoadFile(eCtx, c.ConfigStore, fileList) })
-	return errGroup.Wait()
+	if err := c.checkFrozen(""); err != nil {
+		return err
+	}
+	// the config is passed to the loaders, so loaded keys resolve aliases
+	if err := c.loader.LoadEnv(ctx, c, envPrefixList); err != nil {
+		return err
+	}
+	return c.loader.LoadFile(ctx, c, fileList)
 }
 
 // filters out simple values and nested values
@@ -161,13 +189,13 @@ func (c *Config) Compare(ctx context.Context, cmp *Config, valueCompare bool) er
 		errGroup.Go(func() error {
 			ourValue, err := c.Get(eCtx, k)
 			if err != nil {
-				return &ErrKeyNotFound{key: k}
+				return readError(k, err)
 			} else if !valueCompare {
 				return nil
 			}
 			theirValue, err := cmp.Get(eCtx, k)
 			if err != nil {
-				return &ErrKeyNotFound{key: k}
+				return readError(k, err)
 			} else if strings.Compare(ourValue, theirValue) != 0 {
 				return &ErrValueMismatch{key: k, expected: theirValue, actual: ourValue}
 			}
@@ -186,7 +214,7 @@ func (c *Config) CompareMap(ctx context.Context, cmpMap map[string]string, value
 		errGroup.Go(func() error {
 			value, err := c.Get(eCtx, k)
 			if err != nil {
-				return &ErrKeyNotFound{key: k}
+				return readError(k, err)
 			} else if !valueCompare {
 				return nil
 			} else if strings.Compare(value, cmpMap[k]) != 0 {
@@ -198,44 +226,52 @@ func (c *Config) CompareMap(ctx context.Context, cmpMap map[string]string, value
 	return errGroup.Wait()
 }
 
+// Merge sets all values of merger in a single update.
+// Without overwrite the merge fails if a key already exists and nothing is changed.
 func (c *Config) Merge(ctx context.Context, merger ConfigStore, overwrite bool) error {
-	errGroup, eCtx := errgroup.WithContext(ctx)
-	for _, key := range merger.Keys(ctx) {
-		k := key
-		errGroup.Go(func() error {
-			if c.Has(eCtx, k) && !overwrite {
-				return &ErrKeyInStore{key: k}
+	values := collectValues(ctx, merger, "")
+	return c.Update(ctx, func(tx Tx) error {
+		for key, value := range values {
+			if tx.Has(ctx, key) && !overwrite {
+				return &ErrKeyInStore{key: key}
 			}
-			value, err := merger.Get(eCtx, k)
-			if err != nil {
+			if err := tx.Set(ctx, key, value); err != nil {
 				return err
 			}
-			return c.Set(eCtx, k, value, overwrite)
-		})
-	}
-	return errGroup.Wait()
+		}
+		return nil
+	})
 }
 
+// MergeIn sets all values of value below baseKey in a single update.
+// Without force the merge fails if a key already exists and nothing is changed.
 func (c *Config) MergeIn(ctx context.Context, baseKey string, value ConfigStore, force bool) error {
-	errGroup, eCtx := errgroup.WithContext(ctx)
-	for _, key := range value.Keys(ctx) {
-		k := key
-		errGroup.Go(func() error {
-			joinedKey := baseKey + CONFIG_TREE_SEPARATOR + k
-			val, _ := value.Get(eCtx, k)
-			return c.Set(eCtx, joinedKey, val, force)
-		})
-	}
-	return errGroup.Wait()
+	values := collectValues(ctx, value, "")
+	return c.Update(ctx, func(tx Tx) error {
+		for key, val := range values {
+			joinedKey := baseKey + CONFIG_TREE_SEPARATOR + key
+			if tx.Has(ctx, joinedKey) && !force {
+				return &ErrKeyInStore{key: joinedKey}
+			}
+			if err := tx.Set(ctx, joinedKey, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
+// GetConfig returns a copy of the subtree below key, later changes are not shared.
+// Aliases and set hooks of the subtree are carried over, hooks get the keys relative to the subtree.
+// Use View for a live view on the subtree.
 func (c *Config) GetConfig(ctx context.Context, key string) (*Config, error) {
 	key = strings.TrimSpace(key)
 	key = strings.ToUpper(key)
 	if err := IsValidKey(key); err != nil { // check key is valid
 		return nil, err
 	}
-	store, err := DefaultConfigStore(ctx)
+	key = c.resolveKey(key)
+	store, err := c.createStore(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -254,19 +290,26 @@ func (c *Config) GetConfig(ctx context.Context, key string) (*Config, error) {
 	if err := errGroup.Wait(); err != nil {
 		return nil, err
 	}
+	// aliases and hooks of the subtree apply to the keys relative to it
 	return &Config{
 		loader:      &ConfigLoader{},
+		profile:     c.profile,
+		aliases:     c.aliases.below(key),
+		setHooks:    c.setHooks.below(key),
+		computed:    newComputedKeys(),
+		newStore:    c.newStore,
 		ConfigStore: store,
 	}, nil
 }
 
 func (c *Config) Copy(ctx context.Context) (*Config, error) {
-	buffer, err := DefaultConfigStore(ctx)
+	buffer, err := c.createStore(ctx)
 	if err != nil {
 		return nil, err
 	}
-	for _, key := range c.Keys(ctx) {
-		value, err := c.Get(ctx, key)
+	// computed keys are registered on the copy instead of copying their current value
+	for _, key := range c.ConfigStore.Keys(ctx) {
+		value, err := c.ConfigStore.Get(ctx, key)
 		if err != nil {
 			return nil, ErrCopyConfigReason{err}
 		}
@@ -274,10 +317,119 @@ func (c *Config) Copy(ctx context.Context) (*Config, error) {
 	}
 	return &Config{
 		loader:      &ConfigLoader{},
+		profile:     c.profile,
+		aliases:     c.aliases.clone(),
+		setHooks:    c.setHooks.clone(),
+		computed:    c.computed.clone(),
+		newStore:    c.newStore,
 		ConfigStore: buffer,
 	}, nil
 }
 
+func (c Config) Get(ctx context.Context, key string) (string, error) {
+	key = c.resolveKey(key)
+	if value, ok, err := c.computedValue(ctx, key); ok {
+		return value, err
+	}
+	return c.ConfigStore.Get(ctx, key)
+}
+
+func (c Config) GetAll(ctx context.Context, key string) map[string]string {
+	key = c.resolveKey(key)
+	values := c.ConfigStore.GetAll(ctx, key)
+	if c.computed == nil {
+		return values
+	}
+	// GetAll can not report errors, keys that fail to compute are left out
+	computed, _ := c.computedValues(ctx, key)
+	if len(computed) == 0 {
+		return values
+	}
+	if values == nil {
+		values = make(map[string]string, len(computed))
+	}
+	for suffix, value := range computed {
+		values[suffix] = value
+	}
+	return values
+}
+
+func (c Config) Has(ctx context.Context, key string) bool {
+	key = c.resolveKey(key)
+	if len(c.computedBelow(strings.ToUpper(strings.TrimSpace(key)))) > 0 {
+		return true
+	}
+	return c.ConfigStore.Has(ctx, key)
+}
+
+func (c Config) Set(ctx context.Context, key string, value string, force bool) error {
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	key = c.resolveKey(key)
+	if err := c.checkComputed(key); err != nil {
+		return err
+	}
+	value, err := c.applySetHooks(ctx, c, key, value)
+	if err != nil {
+		return err
+	}
+	return c.ConfigStore.Set(ctx, key, value, force)
+}
+
+func (c Config) Delete(ctx context.Context, key string) error {
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	return c.ConfigStore.Delete(ctx, c.resolveKey(key))
+}
+
+func (c Config) DeletePrefix(ctx context.Context, prefix string) error {
+	if err := c.checkFrozen(prefix); err != nil {
+		return err
+	}
+	return c.ConfigStore.DeletePrefix(ctx, c.resolveKey(prefix))
+}
+
+// SetTyped stores a typed value, stores without typed support only keep the string form
+func (c Config) SetTyped(ctx context.Context, key string, value any, force bool) error {
+	if err := c.checkFrozen(key); err != nil {
+		return err
+	}
+	key = c.resolveKey(key)
+	if err := c.checkComputed(key); err != nil {
+		return err
+	}
+	value, err := c.applyTypedSetHooks(ctx, c, key, value)
+	if err != nil {
+		return err
+	}
+	if store, ok := c.ConfigStore.(TypedStore); ok {
+		return store.SetTyped(ctx, key, value, force)
+	}
+	formatted, err := formatValue(key, value)
+	if err != nil {
+		return err
+	}
+	return c.ConfigStore.Set(ctx, key, formatted, force)
+}
+
+// GetTyped returns the value as it was set, or the string form if the store does not keep typed values
+func (c Config) GetTyped(ctx context.Context, key string) (any, error) {
+	key = c.resolveKey(key)
+	if value, ok, err := c.computedValue(ctx, key); ok {
+		return value, err
+	}
+	return getTyped(ctx, c.ConfigStore, key)
+}
+
+// Keys returns all keys of the config in sorted order, regardless of the store implementation
+func (c Config) Keys(ctx context.Context) []string {
+	keys := append(c.ConfigStore.Keys(ctx), c.computedKeyList()...)
+	slices.SortFunc(keys, CompareKeys)
+	return slices.Compact(keys)
+}
+
 func (c *Config) Sprint() string {
 	buffer := &strings.Builder{}
 	ctx := context.Background()
@@ -288,49 +440,19 @@ func (c *Config) Sprint() string {
 	return buffer.String()
 }
 
+// DumpToFile writes the config to outFile in the given format, see Dump
 func (c *Config) DumpToFile(ctx context.Context, format string, outFile string) error {
 	file, err := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
-		return &ErrDumpToFile{reason: err}
+		return &ErrDumpToFile{file: outFile, reason: err}
 	}
 	defer file.Close()
-	var formattedConfig string
-
-	switch format {
-	// case "json":
-	// 	bytes, err := json.Marshal(c.toInitialMap())
-	// 	if err != nil {
-	// 		return err
-	// 	}
-	// 	formattedConfig = string(bytes)
-	case "env":
-		formattedConfig = c.toEnv(ctx)
-	default:
-		return &ErrDumpToFile{reason: errors.New("invalid or unknown format")}
-	}
-
-	_, err = file.WriteString(formattedConfig)
-	if err != nil {
-		return &ErrDumpToFile{reason: err}
+	if err := c.Dump(ctx, file, format); err != nil {
+		return &ErrDumpToFile{file: outFile, reason: err}
 	}
 	return nil
 }
 
-func (c *Config) toEnv(ctx context.Context) string {
-	var builder strings.Builder
-	for _, key := range c.Keys(ctx) {
-		val, err := c.Get(ctx, key)
-		if err != nil {
-			continue
-		}
-		builder.WriteString(key)
-		builder.WriteString("=")
-		builder.WriteString(val)
-		builder.WriteString("\n")
-	}
-	return builder.String()
-}
-
 func IsValidKey(raw_key string) error {
 	if raw_key == "" {
 		return &ErrKeyValueInvalid{key: raw_key}
@@ -0,0 +1,85 @@
+package configtest
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+// AssertKeys checks that store holds exactly the given keys, reporting missing and unexpected keys
+func AssertKeys(t testing.TB, store config.ConfigStore, keys ...string) {
+	t.Helper()
+	expected := make([]string, 0, len(keys))
+	for _, key := range keys {
+		expected = append(expected, normalizeKey(key))
+	}
+	actual := store.Keys(context.Background())
+	missing := []string{}
+	for _, key := range expected {
+		if !slices.Contains(actual, key) {
+			missing = append(missing, key)
+		}
+	}
+	unexpected := []string{}
+	for _, key := range actual {
+		if !slices.Contains(expected, key) {
+			unexpected = append(unexpected, key)
+		}
+	}
+	if len(missing) == 0 && len(unexpected) == 0 {
+		return
+	}
+	slices.Sort(missing)
+	slices.Sort(unexpected)
+	message := "config keys differ:"
+	if len(missing) > 0 {
+		message += "\n  missing:    " + strings.Join(missing, ", ")
+	}
+	if len(unexpected) > 0 {
+		message += "\n  unexpected: " + strings.Join(unexpected, ", ")
+	}
+	t.Error(message)
+}
+
+// AssertEqual checks that store holds exactly the expected values.
+// Differences are reported as a diff, '-' lines are expected and '+' lines are actual values.
+func AssertEqual(t testing.TB, store config.ConfigStore, expected map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	want := make(map[string]string, len(expected))
+	for key, value := range expected {
+		want[normalizeKey(key)] = value
+	}
+	keys := store.Keys(ctx)
+	for key := range want {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	diff := []string{}
+	for _, key := range keys {
+		wantValue, wanted := want[key]
+		gotValue, err := store.Get(ctx, key)
+		got := err == nil
+		if wanted && got && wantValue == gotValue {
+			continue
+		}
+		if wanted {
+			diff = append(diff, "  -"+key+"="+wantValue)
+		}
+		if got {
+			diff = append(diff, "  +"+key+"="+gotValue)
+		}
+	}
+	if len(diff) > 0 {
+		t.Error("config values differ (-expected +actual):\n" + strings.Join(diff, "\n"))
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.TrimSpace(key))
+}
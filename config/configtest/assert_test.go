@@ -0,0 +1,56 @@
+package configtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+// fakeT records failures instead of failing the test
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Error(args ...any) {
+	f.errors = append(f.errors, fmt.Sprint(args...))
+}
+
+func newAssertStore(t *testing.T) config.ConfigStore {
+	cfg, err := config.WithInitialValues(context.TODO(), map[string]interface{}{
+		"name":   "app",
+		"server": map[string]interface{}{"port": 8080},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestAssertKeys(t *testing.T) {
+	store := newAssertStore(t)
+	AssertKeys(t, store, "name", "SERVER/PORT")
+
+	fake := &fakeT{}
+	AssertKeys(fake, store, "NAME", "LEVEL")
+	expected := "config keys differ:\n  missing:    LEVEL\n  unexpected: SERVER/PORT"
+	if len(fake.errors) != 1 || fake.errors[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, fake.errors)
+	}
+}
+
+func TestAssertEqual(t *testing.T) {
+	store := newAssertStore(t)
+	AssertEqual(t, store, map[string]string{"NAME": "app", "server/port": "8080"})
+
+	fake := &fakeT{}
+	AssertEqual(fake, store, map[string]string{"NAME": "other", "LEVEL": "info"})
+	expected := "config values differ (-expected +actual):\n  -LEVEL=info\n  -NAME=other\n  +NAME=app\n  +SERVER/PORT=8080"
+	if len(fake.errors) != 1 || fake.errors[0] != expected {
+		t.Errorf("Expected %q, got %q", expected, fake.errors)
+	}
+}
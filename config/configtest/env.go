@@ -0,0 +1,79 @@
+// Package configtest provides helpers for testing code that uses the config package:
+// an isolated environment, assertions on stores and stores that record calls or fail on demand.
+package configtest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+// Env is an isolated set of environment variables, loading from it does not read or modify the process environment
+type Env struct {
+	mu   sync.RWMutex
+	vars map[string]string
+}
+
+// NewEnv creates an environment holding vars
+func NewEnv(vars map[string]string) *Env {
+	env := &Env{vars: make(map[string]string, len(vars))}
+	for name, value := range vars {
+		env.vars[name] = value
+	}
+	return env
+}
+
+func (e *Env) Set(name string, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.vars == nil {
+		e.vars = make(map[string]string)
+	}
+	e.vars[name] = value
+}
+
+func (e *Env) Unset(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.vars, name)
+}
+
+func (e *Env) Lookup(name string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	value, ok := e.vars[name]
+	return value, ok
+}
+
+// Environ returns the variables as sorted NAME=value entries, like os.Environ
+func (e *Env) Environ() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	environ := make([]string, 0, len(e.vars))
+	for name, value := range e.vars {
+		environ = append(environ, name+"="+value)
+	}
+	slices.Sort(environ)
+	return environ
+}
+
+// Source returns the variables as an EnvSource, changes to the environment are seen by the source
+func (e *Env) Source() config.EnvSource {
+	return func() ([]string, error) {
+		return e.Environ(), nil
+	}
+}
+
+// Load loads the variables matching prefixList into store, like ConfigLoader.LoadEnv does for the process environment
+func (e *Env) Load(ctx context.Context, store config.ConfigStore, prefixList ...string) error {
+	loader := &config.ConfigLoader{Env: e.Source()}
+	return loader.LoadEnv(ctx, store, prefixList)
+}
+
+// Config creates a config from the variables and files with config.NewLoadedConfig.
+// Variables take precedence over file values.
+func (e *Env) Config(ctx context.Context, envPrefixList []string, fileList []string, opts ...config.Option) (*config.Config, error) {
+	return config.NewLoadedConfig(ctx, envPrefixList, fileList, append(opts, config.WithEnvSource(e.Source()))...)
+}
@@ -0,0 +1,46 @@
+package configtest
+
+import (
+	"context"
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	env := NewEnv(map[string]string{"APP_NAME": "app"})
+	env.Set("APP_SERVER_PORT", "8080")
+	env.Set("OTHER", "x")
+	env.Unset("OTHER")
+	if value, ok := env.Lookup("APP_NAME"); !ok || value != "app" {
+		t.Errorf("Expected 'app', got '%s'", value)
+	}
+	if !slices.Equal(env.Environ(), []string{"APP_NAME=app", "APP_SERVER_PORT=8080"}) {
+		t.Errorf("Unexpected environ %v", env.Environ())
+	}
+	if _, ok := os.LookupEnv("APP_SERVER_PORT"); ok {
+		t.Error("Env must not modify the process environment")
+	}
+
+	store := NewRecordingStore(nil)
+	if err := env.Load(context.TODO(), store, "APP"); err != nil {
+		t.Fatal(err)
+	}
+	AssertEqual(t, store, map[string]string{"NAME": "app", "SERVER/PORT": "8080"})
+}
+
+func TestEnvConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := path.Join(dir, "app.env")
+	if err := os.WriteFile(file, []byte("NAME=file\nLEVEL=info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := NewEnv(map[string]string{"APP_NAME": "env"})
+	cfg, err := env.Config(context.TODO(), []string{"APP"}, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// variables take precedence over files
+	AssertEqual(t, cfg, map[string]string{"NAME": "env", "LEVEL": "info"})
+}
@@ -0,0 +1,94 @@
+package configtest
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+// ErrInjected is returned by a FailingStore without its own error
+var ErrInjected = errors.New("injected config store failure")
+
+// FailingStore fails selected calls, the other calls are passed to the wrapped store.
+// Failing methods without an error result return empty results.
+type FailingStore struct {
+	config.ConfigStore
+	// Err is returned by failing calls, ErrInjected if nil
+	Err error
+	// Fail decides which calls fail, all calls fail if nil
+	Fail func(method string, key string) bool
+}
+
+// NewFailingStore creates an empty store failing the given methods with err, all methods if none are given
+func NewFailingStore(err error, methods ...string) *FailingStore {
+	store, _ := config.NewConfigStore(context.Background())
+	failing := &FailingStore{ConfigStore: store, Err: err}
+	if len(methods) > 0 {
+		failing.Fail = func(method string, key string) bool {
+			return slices.Contains(methods, method)
+		}
+	}
+	return failing
+}
+
+func (s *FailingStore) fails(method string, key string) bool {
+	return s.Fail == nil || s.Fail(method, key)
+}
+
+func (s *FailingStore) err() error {
+	if s.Err == nil {
+		return ErrInjected
+	}
+	return s.Err
+}
+
+func (s *FailingStore) Get(ctx context.Context, key string) (string, error) {
+	if s.fails(METHOD_GET, key) {
+		return "", s.err()
+	}
+	return s.ConfigStore.Get(ctx, key)
+}
+
+func (s *FailingStore) GetAll(ctx context.Context, key string) map[string]string {
+	if s.fails(METHOD_GET_ALL, key) {
+		return map[string]string{}
+	}
+	return s.ConfigStore.GetAll(ctx, key)
+}
+
+func (s *FailingStore) Set(ctx context.Context, key string, value string, force bool) error {
+	if s.fails(METHOD_SET, key) {
+		return s.err()
+	}
+	return s.ConfigStore.Set(ctx, key, value, force)
+}
+
+func (s *FailingStore) Delete(ctx context.Context, key string) error {
+	if s.fails(METHOD_DELETE, key) {
+		return s.err()
+	}
+	return s.ConfigStore.Delete(ctx, key)
+}
+
+func (s *FailingStore) DeletePrefix(ctx context.Context, prefix string) error {
+	if s.fails(METHOD_DELETE_PREFIX, prefix) {
+		return s.err()
+	}
+	return s.ConfigStore.DeletePrefix(ctx, prefix)
+}
+
+func (s *FailingStore) Has(ctx context.Context, key string) bool {
+	if s.fails(METHOD_HAS, key) {
+		return false
+	}
+	return s.ConfigStore.Has(ctx, key)
+}
+
+func (s *FailingStore) Keys(ctx context.Context) []string {
+	if s.fails(METHOD_KEYS, "") {
+		return []string{}
+	}
+	return s.ConfigStore.Keys(ctx)
+}
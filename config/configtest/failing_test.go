@@ -0,0 +1,54 @@
+package configtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+func TestFailingStore(t *testing.T) {
+	ctx := context.TODO()
+	store := NewFailingStore(nil)
+	if err := store.Set(ctx, "KEY", "value", false); !errors.Is(err, ErrInjected) {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+	if _, err := store.Get(ctx, "KEY"); !errors.Is(err, ErrInjected) {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+	if store.Has(ctx, "KEY") || len(store.Keys(ctx)) != 0 || len(store.GetAll(ctx, "KEY")) != 0 {
+		t.Error("Expected empty results")
+	}
+}
+
+func TestFailingStoreMethods(t *testing.T) {
+	ctx := context.TODO()
+	errWrite := errors.New("disk full")
+	store := NewFailingStore(errWrite, METHOD_SET)
+	if err := store.Set(ctx, "KEY", "value", false); !errors.Is(err, errWrite) {
+		t.Errorf("Expected write error, got %v", err)
+	}
+	// errors surface through the config functions
+	source, err := config.WithInitialValues(ctx, map[string]interface{}{"key": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{ConfigStore: store}
+	if err := cfg.Merge(ctx, source, true); !errors.Is(err, errWrite) {
+		t.Errorf("Expected merge to fail with write error, got %v", err)
+	}
+
+	// failures can be limited to single keys
+	store = &FailingStore{
+		ConfigStore: NewRecordingStore(nil),
+		Fail:        func(method string, key string) bool { return key == "SECRET" },
+	}
+	if err := store.Set(ctx, "PUBLIC", "value", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "SECRET"); !errors.Is(err, ErrInjected) {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+	AssertKeys(t, store, "PUBLIC")
+}
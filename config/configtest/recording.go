@@ -0,0 +1,123 @@
+package configtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+// method names used by Call and FailingStore, the same as reported by decorated stores
+const (
+	METHOD_GET           = config.METHOD_GET
+	METHOD_GET_ALL       = config.METHOD_GET_ALL
+	METHOD_SET           = config.METHOD_SET
+	METHOD_DELETE        = config.METHOD_DELETE
+	METHOD_DELETE_PREFIX = config.METHOD_DELETE_PREFIX
+	METHOD_HAS           = config.METHOD_HAS
+	METHOD_KEYS          = config.METHOD_KEYS
+)
+
+// Call is a recorded store call, Value holds the value set or returned
+type Call struct {
+	Method string
+	Key    string
+	Value  string
+	Force  bool
+	Err    error
+}
+
+func (c Call) String() string {
+	switch c.Method {
+	case METHOD_SET:
+		return fmt.Sprintf("Set(%s, %q, %t) -> %v", c.Key, c.Value, c.Force, c.Err)
+	case METHOD_GET:
+		if c.Err != nil {
+			return fmt.Sprintf("Get(%s) -> %v", c.Key, c.Err)
+		}
+		return fmt.Sprintf("Get(%s) -> %q", c.Key, c.Value)
+	default:
+		return fmt.Sprintf("%s(%s) -> %v", c.Method, c.Key, c.Err)
+	}
+}
+
+// RecordingStore wraps a store and records all reads and writes.
+// It does not forward the optional store interfaces, so typed values are set and read as strings
+// and every access is recorded.
+type RecordingStore struct {
+	config.ConfigStore
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecordingStore records the calls to store, a nil store is replaced by an empty one
+func NewRecordingStore(store config.ConfigStore) *RecordingStore {
+	if store == nil {
+		store, _ = config.NewConfigStore(context.Background())
+	}
+	return &RecordingStore{ConfigStore: store}
+}
+
+func (s *RecordingStore) record(call Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// Calls returns the recorded calls in order
+func (s *RecordingStore) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := make([]Call, len(s.calls))
+	copy(calls, s.calls)
+	return calls
+}
+
+// CallsTo returns the recorded calls of a method, e.g. METHOD_SET
+func (s *RecordingStore) CallsTo(method string) []Call {
+	calls := []Call{}
+	for _, call := range s.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears the recorded calls
+func (s *RecordingStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+func (s *RecordingStore) Get(ctx context.Context, key string) (string, error) {
+	value, err := s.ConfigStore.Get(ctx, key)
+	s.record(Call{Method: METHOD_GET, Key: key, Value: value, Err: err})
+	return value, err
+}
+
+func (s *RecordingStore) GetAll(ctx context.Context, key string) map[string]string {
+	values := s.ConfigStore.GetAll(ctx, key)
+	s.record(Call{Method: METHOD_GET_ALL, Key: key})
+	return values
+}
+
+func (s *RecordingStore) Set(ctx context.Context, key string, value string, force bool) error {
+	err := s.ConfigStore.Set(ctx, key, value, force)
+	s.record(Call{Method: METHOD_SET, Key: key, Value: value, Force: force, Err: err})
+	return err
+}
+
+func (s *RecordingStore) Delete(ctx context.Context, key string) error {
+	err := s.ConfigStore.Delete(ctx, key)
+	s.record(Call{Method: METHOD_DELETE, Key: key, Err: err})
+	return err
+}
+
+func (s *RecordingStore) DeletePrefix(ctx context.Context, prefix string) error {
+	err := s.ConfigStore.DeletePrefix(ctx, prefix)
+	s.record(Call{Method: METHOD_DELETE_PREFIX, Key: prefix, Err: err})
+	return err
+}
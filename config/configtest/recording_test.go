@@ -0,0 +1,55 @@
+package configtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+func TestRecordingStore(t *testing.T) {
+	ctx := context.TODO()
+	store := NewRecordingStore(nil)
+	if err := store.Set(ctx, "NAME", "app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "NAME", "other", false); err == nil {
+		t.Error("Expected error for existing key")
+	}
+	if _, err := store.Get(ctx, "NAME"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "MISSING"); err == nil {
+		t.Error("Expected error for missing key")
+	}
+	// typed values are recorded as strings
+	if err := config.Set(ctx, store, "PORT", 8080, false); err != nil {
+		t.Fatal(err)
+	}
+	if port, err := config.Get[int](ctx, store, "PORT"); err != nil || port != 8080 {
+		t.Errorf("Expected 8080, got %d (%v)", port, err)
+	}
+
+	calls := store.Calls()
+	if len(calls) != 6 {
+		t.Fatalf("Expected 6 calls, got %v", calls)
+	}
+	if calls[0].String() != `Set(NAME, "app", false) -> <nil>` {
+		t.Errorf("Unexpected call %s", calls[0])
+	}
+	var inStore *config.ErrKeyInStore
+	if !errors.As(calls[1].Err, &inStore) {
+		t.Errorf("Expected recorded error, got %s", calls[1])
+	}
+	if calls[2].String() != `Get(NAME) -> "app"` {
+		t.Errorf("Unexpected call %s", calls[2])
+	}
+	if sets := store.CallsTo(METHOD_SET); len(sets) != 3 || sets[2].Key != "PORT" || sets[2].Value != "8080" {
+		t.Errorf("Unexpected set calls %v", sets)
+	}
+	store.Reset()
+	if len(store.Calls()) != 0 {
+		t.Error("Expected no calls after reset")
+	}
+}
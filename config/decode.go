@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// TIME_LAYOUTS are the layouts accepted when decoding time.Time values
+var TIME_LAYOUTS = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[reflect.Type]func(string) (any, error){}
+)
+
+func init() {
+	RegisterDecoder(ParseDuration)
+	RegisterDecoder(decodeTime)
+	RegisterDecoder(url.Parse)
+	RegisterDecoder(func(raw string) (url.URL, error) {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *parsed, nil
+	})
+	RegisterDecoder(decodeIP)
+	RegisterDecoder(netip.ParsePrefix)
+	RegisterDecoder(regexp.Compile)
+	RegisterDecoder(func(raw string) (regexp.Regexp, error) {
+		compiled, err := regexp.Compile(raw)
+		if err != nil {
+			return regexp.Regexp{}, err
+		}
+		return *compiled, nil
+	})
+}
+
+// RegisterDecoder registers decode for values of type T, used by Get and GetOr.
+// A decoder registered for a type replaces the previous one, including the built-in decoders.
+func RegisterDecoder[T any](decode func(string) (T, error)) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[reflect.TypeOf((*T)(nil)).Elem()] = func(raw string) (any, error) {
+		return decode(raw)
+	}
+}
+
+func lookupDecoder(target reflect.Type) (func(string) (any, error), bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	decode, ok := decoders[target]
+	return decode, ok
+}
+
+// GetOr returns the value of key as T, or fallback if the key is not set or can't be decoded
+func GetOr[T any](ctx context.Context, store ConfigStore, key string, fallback T) T {
+	value, err := Get[T](ctx, store, key)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func decodeTime(raw string) (time.Time, error) {
+	var err error
+	for _, layout := range TIME_LAYOUTS {
+		var parsed time.Time
+		if parsed, err = time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func decodeIP(raw string) (net.IP, error) {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return nil, errors.New("invalid IP address: " + raw)
+	}
+	return ip, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoders(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"timeout": "1m30s",
+		"start":   "2024-01-02T03:04:05Z",
+		"day":     "2024-01-02",
+		"url":     "https://example.com/path?q=1",
+		"ip":      "10.0.0.1",
+		"net":     "10.0.0.0/8",
+		"pattern": "^a+$",
+		"port":    "8080",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get[time.Duration](ctx, config, "TIMEOUT"); err != nil || value != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %s (%v)", value, err)
+	}
+	if value, err := Get[time.Time](ctx, config, "START"); err != nil || !value.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected time %s (%v)", value, err)
+	}
+	if value, err := Get[time.Time](ctx, config, "DAY"); err != nil || value.Day() != 2 {
+		t.Errorf("Unexpected date %s (%v)", value, err)
+	}
+	if value, err := Get[*url.URL](ctx, config, "URL"); err != nil || value.Host != "example.com" {
+		t.Errorf("Unexpected url %v (%v)", value, err)
+	}
+	if value, err := Get[url.URL](ctx, config, "URL"); err != nil || value.Path != "/path" {
+		t.Errorf("Unexpected url %v (%v)", value, err)
+	}
+	if value, err := Get[net.IP](ctx, config, "IP"); err != nil || !value.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Unexpected ip %v (%v)", value, err)
+	}
+	if value, err := Get[netip.Prefix](ctx, config, "NET"); err != nil || value.Bits() != 8 {
+		t.Errorf("Unexpected prefix %v (%v)", value, err)
+	}
+	if value, err := Get[*regexp.Regexp](ctx, config, "PATTERN"); err != nil || !value.MatchString("aaa") {
+		t.Errorf("Unexpected pattern %v (%v)", value, err)
+	}
+	if value, err := Get[uint16](ctx, config, "PORT"); err != nil || value != 8080 {
+		t.Errorf("Expected 8080, got %d (%v)", value, err)
+	}
+	if _, err := Get[net.IP](ctx, config, "PATTERN"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"timeout": "5s", "broken": "soon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := GetOr(ctx, config, "TIMEOUT", time.Minute); value != 5*time.Second {
+		t.Errorf("Expected 5s, got %s", value)
+	}
+	if value := GetOr(ctx, config, "MISSING", time.Minute); value != time.Minute {
+		t.Errorf("Expected fallback, got %s", value)
+	}
+	if value := GetOr(ctx, config, "BROKEN", time.Minute); value != time.Minute {
+		t.Errorf("Expected fallback, got %s", value)
+	}
+}
+
+type testLevel int
+
+func TestRegisterDecoder(t *testing.T) {
+	ctx := context.TODO()
+	RegisterDecoder(func(raw string) (testLevel, error) {
+		switch strings.ToLower(raw) {
+		case "low":
+			return 1, nil
+		case "high":
+			return 2, nil
+		}
+		return 0, errors.New("unknown level")
+	})
+	config, err := WithInitialValues(ctx, map[string]interface{}{"level": "HIGH", "other": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get[testLevel](ctx, config, "LEVEL"); err != nil || value != 2 {
+		t.Errorf("Expected 2, got %d (%v)", value, err)
+	}
+	if _, err := Get[testLevel](ctx, config, "OTHER"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+}
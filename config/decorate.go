@@ -0,0 +1,359 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// store methods reported to hooks and metrics of decorated stores
+const (
+	METHOD_GET           = "Get"
+	METHOD_GET_ALL       = "GetAll"
+	METHOD_GET_TYPED     = "GetTyped"
+	METHOD_SET           = "Set"
+	METHOD_SET_TYPED     = "SetTyped"
+	METHOD_DELETE        = "Delete"
+	METHOD_DELETE_PREFIX = "DeletePrefix"
+	METHOD_HAS           = "Has"
+	METHOD_KEYS          = "Keys"
+	METHOD_UPDATE        = "Update"
+	METHOD_ROLLBACK      = "Rollback"
+)
+
+// StoreOp is an operation on a decorated store, Key is empty for Keys, Update and Rollback
+type StoreOp struct {
+	Method string
+	Key    string
+}
+
+// BeforeHook is called before an operation, the returned context is passed on to the store.
+// Returning an error skips the operation, Has then reports false and Keys no keys.
+type BeforeHook func(ctx context.Context, op StoreOp) (context.Context, error)
+
+// AfterHook is called after every operation with its error
+type AfterHook func(ctx context.Context, op StoreOp, err error)
+
+// StoreMetric describes a finished operation, Miss is set for reads of keys that do not exist
+type StoreMetric struct {
+	StoreOp
+	Duration time.Duration
+	Miss     bool
+	Err      error
+}
+
+// MetricsRecorder receives a metric for each operation of a store wrapped with WithMetrics
+type MetricsRecorder func(ctx context.Context, metric StoreMetric)
+
+// WithHooks calls before and after around every operation on store, e.g. to trace access.
+// Either hook may be nil.
+func WithHooks(store ConfigStore, before BeforeHook, after AfterHook) ConfigStore {
+	return decorate(store, func(ctx context.Context, op StoreOp, next func(context.Context) storeResult) storeResult {
+		var result storeResult
+		if before != nil {
+			hookCtx, err := before(ctx, op)
+			if hookCtx != nil {
+				ctx = hookCtx
+			}
+			result.err = err
+		}
+		if result.err == nil {
+			result = next(ctx)
+		}
+		if after != nil {
+			after(ctx, op, result.err)
+		}
+		return result
+	})
+}
+
+// WithMetrics passes a metric for every operation on store to recorder
+func WithMetrics(store ConfigStore, recorder MetricsRecorder) ConfigStore {
+	return decorate(store, func(ctx context.Context, op StoreOp, next func(context.Context) storeResult) storeResult {
+		start := time.Now()
+		result := next(ctx)
+		recorder(ctx, StoreMetric{StoreOp: op, Duration: time.Since(start), Miss: result.miss(op), Err: result.err})
+		return result
+	})
+}
+
+// WithCache caches the reads of store for ttl, e.g. for slow remote stores.
+// Writes through the cache clear it, changes made to store directly are seen once entries expire.
+// With a ttl of zero or less entries are kept until the next write.
+// Typed reads are not cached, their values could be shared with and modified by callers.
+func WithCache(store ConfigStore, ttl time.Duration) ConfigStore {
+	cache := &storeCache{ttl: ttl, now: time.Now, entries: make(map[StoreOp]cacheEntry)}
+	return decorate(store, cache.handle)
+}
+
+// storeResult holds the return values of any store method
+type storeResult struct {
+	value  string
+	values map[string]string
+	typed  any
+	has    bool
+	keys   []string
+	err    error
+}
+
+// miss checks if the result of a read reports a missing key
+func (r storeResult) miss(op StoreOp) bool {
+	var notFound *ErrKeyNotFound
+	switch op.Method {
+	case METHOD_GET, METHOD_GET_TYPED:
+		return errors.As(r.err, &notFound)
+	case METHOD_GET_ALL:
+		return r.values == nil
+	case METHOD_HAS:
+		return !r.has
+	}
+	return false
+}
+
+// clone copies the maps and slices of a result, so cached results can not be modified by callers
+func (r storeResult) clone() storeResult {
+	r.values = maps.Clone(r.values)
+	r.keys = slices.Clone(r.keys)
+	return r
+}
+
+// storeHandler runs an operation, next calls the decorated store
+type storeHandler func(ctx context.Context, op StoreOp, next func(context.Context) storeResult) storeResult
+
+// decorate wraps store with handle, optional interfaces of store stay available
+func decorate(store ConfigStore, handle storeHandler) ConfigStore {
+	decorated := &decoratedStore{next: store, handle: handle}
+	if versioned, ok := store.(VersionedStore); ok {
+		return &decoratedVersionedStore{decoratedStore: decorated, versioned: versioned}
+	}
+	return decorated
+}
+
+// decoratedStore passes every operation through handle.
+// It is always a TxStore and TypedStore, falling back to the generic implementations like Config does.
+type decoratedStore struct {
+	next   ConfigStore
+	handle storeHandler
+}
+
+func (d *decoratedStore) Get(ctx context.Context, key string) (string, error) {
+	result := d.handle(ctx, StoreOp{Method: METHOD_GET, Key: key}, func(ctx context.Context) storeResult {
+		value, err := d.next.Get(ctx, key)
+		return storeResult{value: value, err: err}
+	})
+	return result.value, result.err
+}
+
+func (d *decoratedStore) GetAll(ctx context.Context, key string) map[string]string {
+	return d.handle(ctx, StoreOp{Method: METHOD_GET_ALL, Key: key}, func(ctx context.Context) storeResult {
+		return storeResult{values: d.next.GetAll(ctx, key)}
+	}).values
+}
+
+func (d *decoratedStore) GetTyped(ctx context.Context, key string) (any, error) {
+	result := d.handle(ctx, StoreOp{Method: METHOD_GET_TYPED, Key: key}, func(ctx context.Context) storeResult {
+		value, err := getTyped(ctx, d.next, key)
+		return storeResult{typed: value, err: err}
+	})
+	return result.typed, result.err
+}
+
+func (d *decoratedStore) Set(ctx context.Context, key string, value string, force bool) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_SET, Key: key}, func(ctx context.Context) storeResult {
+		return storeResult{err: d.next.Set(ctx, key, value, force)}
+	}).err
+}
+
+func (d *decoratedStore) SetTyped(ctx context.Context, key string, value any, force bool) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_SET_TYPED, Key: key}, func(ctx context.Context) storeResult {
+		return storeResult{err: Set(ctx, d.next, key, value, force)}
+	}).err
+}
+
+func (d *decoratedStore) Delete(ctx context.Context, key string) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_DELETE, Key: key}, func(ctx context.Context) storeResult {
+		return storeResult{err: d.next.Delete(ctx, key)}
+	}).err
+}
+
+func (d *decoratedStore) DeletePrefix(ctx context.Context, prefix string) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_DELETE_PREFIX, Key: prefix}, func(ctx context.Context) storeResult {
+		return storeResult{err: d.next.DeletePrefix(ctx, prefix)}
+	}).err
+}
+
+func (d *decoratedStore) Has(ctx context.Context, key string) bool {
+	return d.handle(ctx, StoreOp{Method: METHOD_HAS, Key: key}, func(ctx context.Context) storeResult {
+		return storeResult{has: d.next.Has(ctx, key)}
+	}).has
+}
+
+func (d *decoratedStore) Keys(ctx context.Context) []string {
+	return d.handle(ctx, StoreOp{Method: METHOD_KEYS}, func(ctx context.Context) storeResult {
+		return storeResult{keys: d.next.Keys(ctx)}
+	}).keys
+}
+
+// Update is handled as a single operation, the reads and writes of the transaction are not reported
+func (d *decoratedStore) Update(ctx context.Context, fn func(tx Tx) error) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_UPDATE}, func(ctx context.Context) storeResult {
+		if store, ok := d.next.(TxStore); ok {
+			return storeResult{err: store.Update(ctx, fn)}
+		}
+		return storeResult{err: updateStore(ctx, d.next, fn)}
+	}).err
+}
+
+// decoratedVersionedStore forwards versioning, snapshots are taken from the underlying store and not decorated
+type decoratedVersionedStore struct {
+	*decoratedStore
+	versioned VersionedStore
+}
+
+func (d *decoratedVersionedStore) Version() uint64 {
+	return d.versioned.Version()
+}
+
+func (d *decoratedVersionedStore) Snapshot() ConfigStore {
+	return d.versioned.Snapshot()
+}
+
+func (d *decoratedVersionedStore) Rollback(ctx context.Context, version uint64) error {
+	return d.handle(ctx, StoreOp{Method: METHOD_ROLLBACK}, func(ctx context.Context) storeResult {
+		return storeResult{err: d.versioned.Rollback(ctx, version)}
+	}).err
+}
+
+// storeCache holds the results of reads by operation
+type storeCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	now func() time.Time
+	// generation is bumped by every write, reads that started before are not stored
+	generation uint64
+	entries    map[StoreOp]cacheEntry
+}
+
+type cacheEntry struct {
+	result  storeResult
+	expires time.Time
+}
+
+func (c *storeCache) handle(ctx context.Context, op StoreOp, next func(context.Context) storeResult) storeResult {
+	switch op.Method {
+	case METHOD_GET, METHOD_GET_ALL, METHOD_HAS, METHOD_KEYS:
+	case METHOD_GET_TYPED:
+		return next(ctx)
+	default:
+		// writes may change any cached read, e.g. setting A/B changes Get(A), Has(A) and Keys
+		result := next(ctx)
+		c.clear()
+		return result
+	}
+	op.Key = strings.ToUpper(strings.TrimSpace(op.Key))
+	result, generation, ok := c.lookup(op)
+	if ok {
+		return result
+	}
+	result = next(ctx)
+	var notFound *ErrKeyNotFound
+	if ctx.Err() == nil && (result.err == nil || errors.As(result.err, &notFound)) {
+		c.store(op, result, generation)
+	}
+	return result.clone()
+}
+
+// lookup returns the cached result of op, or the current generation to store the result of a new read
+func (c *storeCache) lookup(op StoreOp) (storeResult, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[op]
+	if !ok {
+		return storeResult{}, c.generation, false
+	}
+	if c.ttl > 0 && !c.now().Before(entry.expires) {
+		delete(c.entries, op)
+		return storeResult{}, c.generation, false
+	}
+	return entry.result.clone(), c.generation, true
+}
+
+// store caches result, unless a write happened since the read started
+func (c *storeCache) store(op StoreOp, result storeResult, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[op] = cacheEntry{result: result.clone(), expires: c.now().Add(c.ttl)}
+}
+
+func (c *storeCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+// StoreCounters counts the operations recorded with Record per key prefix
+type StoreCounters struct {
+	mu     sync.Mutex
+	depth  int
+	counts map[string]*StoreCounts
+}
+
+// StoreCounts are the counters of a key prefix
+type StoreCounts struct {
+	Gets    uint64
+	Sets    uint64
+	Deletes uint64
+	Misses  uint64
+	Errors  uint64
+}
+
+// NewStoreCounters creates counters grouping keys by their first depth segments,
+// Record can be passed to WithMetrics
+func NewStoreCounters(depth int) *StoreCounters {
+	return &StoreCounters{depth: max(depth, 1), counts: make(map[string]*StoreCounts)}
+}
+
+func (s *StoreCounters) Record(ctx context.Context, metric StoreMetric) {
+	segments := strings.Split(strings.ToUpper(strings.TrimSpace(metric.Key)), CONFIG_TREE_SEPARATOR)
+	prefix := strings.Join(segments[:min(s.depth, len(segments))], CONFIG_TREE_SEPARATOR)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts, ok := s.counts[prefix]
+	if !ok {
+		counts = &StoreCounts{}
+		s.counts[prefix] = counts
+	}
+	switch metric.Method {
+	case METHOD_GET, METHOD_GET_ALL, METHOD_GET_TYPED, METHOD_HAS:
+		counts.Gets++
+	case METHOD_SET, METHOD_SET_TYPED:
+		counts.Sets++
+	case METHOD_DELETE, METHOD_DELETE_PREFIX:
+		counts.Deletes++
+	}
+	if metric.Miss {
+		counts.Misses++
+	} else if metric.Err != nil {
+		counts.Errors++
+	}
+}
+
+// Counts returns a copy of the counters by prefix, operations without a key are counted under ""
+func (s *StoreCounters) Counts() map[string]StoreCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]StoreCounts, len(s.counts))
+	for prefix, c := range s.counts {
+		counts[prefix] = *c
+	}
+	return counts
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// opLog collects the operations reaching a store
+type opLog struct {
+	mu  sync.Mutex
+	ops []StoreOp
+}
+
+func (l *opLog) after(ctx context.Context, op StoreOp, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ops = append(l.ops, op)
+}
+
+func (l *opLog) count(method string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := 0
+	for _, op := range l.ops {
+		if op.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWithCache(t *testing.T) {
+	ctx := context.TODO()
+	base, err := WithInitialValues(ctx, map[string]interface{}{"db": map[string]interface{}{"host": "localhost"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &opLog{}
+	store := WithCache(WithHooks(base, nil, log.after), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if value, err := store.Get(ctx, "db/host"); err != nil || value != "localhost" {
+			t.Fatalf("Unexpected value '%s' (%v)", value, err)
+		}
+		if _, err := store.Get(ctx, "MISSING"); !errors.Is(err, ErrConfigKey) {
+			t.Fatalf("Expected key error, got %v", err)
+		}
+	}
+	if log.count(METHOD_GET) != 2 {
+		t.Errorf("Expected reads to be cached, got %d gets", log.count(METHOD_GET))
+	}
+
+	// cached results can not be modified by callers
+	store.Keys(ctx)[0] = "CHANGED"
+	if keys := store.Keys(ctx); !slices.Equal(keys, []string{"DB/HOST"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	// writes clear the cache
+	if err := store.Set(ctx, "MISSING", "set", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get(ctx, "MISSING"); err != nil || value != "set" {
+		t.Errorf("Expected written value, got '%s' (%v)", value, err)
+	}
+	if keys := store.Keys(ctx); !slices.Equal(keys, []string{"DB/HOST", "MISSING"}) {
+		t.Errorf("Unexpected keys after write %v", keys)
+	}
+
+	// direct changes are seen once entries expire
+	now := time.Now()
+	storeCache := &storeCache{ttl: time.Minute, now: func() time.Time { return now }, entries: make(map[StoreOp]cacheEntry)}
+	store = decorate(base, storeCache.handle)
+	if _, err := store.Get(ctx, "DB/HOST"); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.Set(ctx, "DB/HOST", "remote", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := store.Get(ctx, "DB/HOST"); value != "localhost" {
+		t.Errorf("Expected cached value, got '%s'", value)
+	}
+	now = now.Add(time.Minute)
+	if value, _ := store.Get(ctx, "DB/HOST"); value != "remote" {
+		t.Errorf("Expected expired entry to be read again, got '%s'", value)
+	}
+}
+
+func TestWithCacheConcurrentWrite(t *testing.T) {
+	ctx := context.TODO()
+	cache := &storeCache{now: time.Now, entries: make(map[StoreOp]cacheEntry)}
+	get := StoreOp{Method: METHOD_GET, Key: "KEY"}
+	// a write finishes while the read is running, the stale result must not be cached
+	cache.handle(ctx, get, func(ctx context.Context) storeResult {
+		cache.handle(ctx, StoreOp{Method: METHOD_SET, Key: "KEY"}, func(ctx context.Context) storeResult {
+			return storeResult{}
+		})
+		return storeResult{value: "stale"}
+	})
+	result := cache.handle(ctx, get, func(ctx context.Context) storeResult {
+		return storeResult{value: "fresh"}
+	})
+	if result.value != "fresh" {
+		t.Errorf("Expected read after the write, got '%s'", result.value)
+	}
+
+	// typed values are not cached, they may be shared with callers
+	typed := StoreOp{Method: METHOD_GET_TYPED, Key: "KEY"}
+	reads := 0
+	for i := 0; i < 2; i++ {
+		cache.handle(ctx, typed, func(ctx context.Context) storeResult {
+			reads++
+			return storeResult{typed: []string{"a"}}
+		})
+	}
+	if reads != 2 {
+		t.Errorf("Expected typed reads not to be cached, got %d reads", reads)
+	}
+}
+
+func TestWithHooks(t *testing.T) {
+	ctx := context.TODO()
+	base, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type traceKey struct{}
+	errDenied := errors.New("denied")
+	traced := []string{}
+	before := func(ctx context.Context, op StoreOp) (context.Context, error) {
+		if op.Key == "SECRET" {
+			return ctx, errDenied
+		}
+		return context.WithValue(ctx, traceKey{}, op.Method+" "+op.Key), nil
+	}
+	after := func(ctx context.Context, op StoreOp, err error) {
+		trace, _ := ctx.Value(traceKey{}).(string)
+		traced = append(traced, trace)
+	}
+	store := WithHooks(base, before, after)
+	if err := store.Set(ctx, "KEY", "value", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "SECRET", "value", false); !errors.Is(err, errDenied) {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+	if store.Has(ctx, "SECRET") || base.Has(ctx, "SECRET") {
+		t.Error("Expected denied operations to be skipped")
+	}
+	if !slices.Equal(traced, []string{"Set KEY", "", ""}) {
+		t.Errorf("Unexpected traces %q", traced)
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctx := context.TODO()
+	base, err := WithInitialValues(ctx, map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := NewStoreCounters(1)
+	store := WithMetrics(base, counters.Record)
+	store.Get(ctx, "DB/HOST")
+	store.Get(ctx, "DB/USER")
+	store.Has(ctx, "CACHE/SIZE")
+	store.Set(ctx, "db/user", "admin", false)
+	store.Set(ctx, "DB/HOST", "other", false)
+	store.Delete(ctx, "CACHE")
+	store.Keys(ctx)
+
+	expected := map[string]StoreCounts{
+		"DB":    {Gets: 2, Sets: 2, Misses: 1, Errors: 1},
+		"CACHE": {Gets: 1, Deletes: 1, Misses: 1, Errors: 1},
+		"":      {},
+	}
+	counts := counters.Counts()
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+	for prefix, count := range expected {
+		if counts[prefix] != count {
+			t.Errorf("Expected %+v for '%s', got %+v", count, prefix, counts[prefix])
+		}
+	}
+}
+
+func TestDecoratedInterfaces(t *testing.T) {
+	ctx := context.TODO()
+	base, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counters := NewStoreCounters(1)
+	store := WithMetrics(WithCache(base, 0), counters.Record)
+	if _, ok := store.(VersionedStore); !ok {
+		t.Fatal("Expected versioning to be forwarded")
+	}
+	config := &Config{ConfigStore: store}
+	if err := Set(ctx, config, "PORT", 8080, false); err != nil {
+		t.Fatal(err)
+	}
+	if port, err := Get[int](ctx, config, "PORT"); err != nil || port != 8080 {
+		t.Errorf("Unexpected typed value %d (%v)", port, err)
+	}
+	version := config.Version()
+	if err := config.Update(ctx, func(tx Tx) error {
+		return tx.Set(ctx, "PORT", "9090")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := Get[int](ctx, config, "PORT"); port != 9090 {
+		t.Errorf("Expected update to clear the cache, got %d", port)
+	}
+	if err := config.Rollback(ctx, version); err != nil {
+		t.Fatal(err)
+	}
+	if port, _ := Get[int](ctx, config, "PORT"); port != 8080 {
+		t.Errorf("Expected rollback to clear the cache, got %d", port)
+	}
+
+	// stores without versioning are not reported as versioned
+	plain := WithCache(&prefixStore{parent: base, prefix: "SUB"}, 0)
+	if _, ok := plain.(VersionedStore); ok {
+		t.Error("Expected no versioning for a plain store")
+	}
+}
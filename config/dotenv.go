@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	DOTENV_EXPORT_PREFIX = "export"
+	DOTENV_COMMENT_CHAR  = '#'
+)
+
+type dotenvEntry struct {
+	key   string
+	value string
+	line  int
+	// raw is the first source line of the entry
+	raw string
+	// include marks an include directive, value holds the included path
+	include  bool
+	optional bool
+}
+
+// parseDotenv parses dotenv formatted content.
+// Supported are blank lines, full line and inline comments, an optional export prefix,
+// unquoted, single quoted (literal), backtick quoted (literal) and double quoted values.
+// Double quoted values may contain escape sequences and span multiple lines.
+// Entries are returned in the order they appear in the input.
+// Parsing continues after syntax errors, all of them are returned as ErrFileLine for filePath.
+func parseDotenv(r io.Reader, filePath string) ([]dotenvEntry, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotenvParser{
+		src:  strings.ReplaceAll(string(raw), "\r\n", "\n"),
+		line: 1,
+	}
+	entries := []dotenvEntry{}
+	errs := []error{}
+	for !p.done() {
+		lineStart, line := p.pos, p.line
+		entry, ok, err := p.next()
+		if err != nil {
+			raw, _, _ := strings.Cut(p.src[lineStart:], "\n")
+			var syntaxErr *ErrDotenvSyntax
+			if errors.As(err, &syntaxErr) {
+				// the line is reported by ErrFileLine
+				syntaxErr.line = 0
+			}
+			errs = append(errs, &ErrFileLine{path: filePath, line: line, raw: raw, nested: err})
+			continue
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+// restOfLine returns the remainder of the current line and moves past its line break
+func (p *dotenvParser) restOfLine() string {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		rest := p.src[p.pos:]
+		p.pos = len(p.src)
+		return rest
+	}
+	rest := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	p.line++
+	return rest
+}
+
+// next parses the next entry, ok is false if the line held no entry (blank or comment)
+func (p *dotenvParser) next() (dotenvEntry, bool, error) {
+	startLine := p.line
+	lineStart := p.pos
+	rawLine := p.restOfLine()
+	line := strings.TrimLeft(rawLine, " \t")
+	if line == "" || line[0] == DOTENV_COMMENT_CHAR {
+		return dotenvEntry{}, false, nil
+	}
+	if target, optional, ok := parseIncludeDirective(line); ok {
+		return dotenvEntry{value: target, line: startLine, raw: rawLine, include: true, optional: optional}, true, nil
+	}
+	if rest, ok := strings.CutPrefix(line, DOTENV_EXPORT_PREFIX); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		line = strings.TrimLeft(rest, " \t")
+	}
+
+	split := strings.IndexAny(line, "=:")
+	if split < 0 {
+		return dotenvEntry{}, false, &ErrDotenvSyntax{line: startLine, reason: "missing separator"}
+	}
+	key := strings.TrimRight(line[:split], " \t")
+	if key == "" {
+		return dotenvEntry{}, false, &ErrDotenvSyntax{line: startLine, reason: "missing key"}
+	}
+	if strings.ContainsAny(key, " \t") {
+		return dotenvEntry{}, false, &ErrDotenvSyntax{line: startLine, reason: "whitespace in key: " + key}
+	}
+	rawValue := strings.TrimLeft(line[split+1:], " \t")
+	entry := dotenvEntry{key: key, line: startLine, raw: rawLine}
+
+	if rawValue == "" || !strings.ContainsRune("\"'`", rune(rawValue[0])) {
+		entry.value = stripInlineComment(rawValue)
+		return entry, true, nil
+	}
+
+	// quoted values may span lines, so continue scanning the source from the opening quote
+	p.pos = lineStart + len(rawLine) - len(rawValue) + 1
+	p.line = startLine
+	value, err := p.quoted(rawValue[0])
+	if err != nil {
+		return dotenvEntry{}, false, err
+	}
+	trailing := strings.TrimLeft(p.restOfLine(), " \t")
+	if trailing != "" && trailing[0] != DOTENV_COMMENT_CHAR {
+		return dotenvEntry{}, false, &ErrDotenvSyntax{line: startLine, reason: "unexpected characters after quoted value: " + trailing}
+	}
+	entry.value = value
+	return entry, true, nil
+}
+
+// quoted reads a value up to the closing quote, the opening quote must already be consumed
+func (p *dotenvParser) quoted(quote byte) (string, error) {
+	startLine := p.line
+	buffer := strings.Builder{}
+	for !p.done() {
+		char := p.src[p.pos]
+		p.pos++
+		switch {
+		case char == quote:
+			return buffer.String(), nil
+		case char == '\n':
+			p.line++
+			buffer.WriteByte(char)
+		case char == '\\' && quote == '"' && !p.done():
+			buffer.WriteString(unescapeDotenv(p.src[p.pos]))
+			if p.src[p.pos] == '\n' {
+				p.line++
+			}
+			p.pos++
+		default:
+			buffer.WriteByte(char)
+		}
+	}
+	return "", &ErrDotenvSyntax{line: startLine, reason: "unterminated quoted value"}
+}
+
+func unescapeDotenv(char byte) string {
+	switch char {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$', '\'':
+		return string(char)
+	case '\n':
+		// escaped line break continues the value on the next line
+		return ""
+	default:
+		return "\\" + string(char)
+	}
+}
+
+// stripInlineComment removes a trailing comment, which has to be separated by whitespace
+func stripInlineComment(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] != DOTENV_COMMENT_CHAR {
+			continue
+		}
+		if i == 0 || value[i-1] == ' ' || value[i-1] == '\t' {
+			value = value[:i]
+			break
+		}
+	}
+	return strings.TrimRight(value, " \t")
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// dotenvKV holds the fields of a parsed key value entry
+type dotenvKV struct {
+	key   string
+	value string
+	line  int
+}
+
+func toDotenvKV(entries []dotenvEntry) []dotenvKV {
+	kvs := make([]dotenvKV, 0, len(entries))
+	for _, entry := range entries {
+		kvs = append(kvs, dotenvKV{entry.key, entry.value, entry.line})
+	}
+	return kvs
+}
+
+func TestParseDotenv(t *testing.T) {
+	corpus := []struct {
+		name     string
+		input    string
+		expected []dotenvKV
+	}{
+		{"simple", "KEY=value", []dotenvKV{{"KEY", "value", 1}}},
+		{"colon separator", "KEY: value", []dotenvKV{{"KEY", "value", 1}}},
+		{"empty value", "KEY=", []dotenvKV{{"KEY", "", 1}}},
+		{"blank lines and comments", "\n# comment\n   \n\t# indented comment\nKEY=value\n", []dotenvKV{{"KEY", "value", 5}}},
+		{"export prefix", "export KEY=value", []dotenvKV{{"KEY", "value", 1}}},
+		{"export tab prefix", "export\tKEY=value", []dotenvKV{{"KEY", "value", 1}}},
+		{"export as key", "export=value", []dotenvKV{{"export", "value", 1}}},
+		{"surrounding whitespace", "  KEY  =   value   ", []dotenvKV{{"KEY", "value", 1}}},
+		{"inline comment", "KEY=value # comment", []dotenvKV{{"KEY", "value", 1}}},
+		{"hash without whitespace", "KEY=val#ue", []dotenvKV{{"KEY", "val#ue", 1}}},
+		{"only comment value", "KEY=# comment", []dotenvKV{{"KEY", "", 1}}},
+		{"equals in value", "KEY=a=b=c", []dotenvKV{{"KEY", "a=b=c", 1}}},
+		{"url value", "KEY=http://host:8080/path", []dotenvKV{{"KEY", "http://host:8080/path", 1}}},
+		{"single quotes", "KEY='value # no comment'", []dotenvKV{{"KEY", "value # no comment", 1}}},
+		{"single quotes literal", `KEY='a\nb'`, []dotenvKV{{"KEY", `a\nb`, 1}}},
+		{"backtick quotes", "KEY=`it's \"quoted\"`", []dotenvKV{{"KEY", `it's "quoted"`, 1}}},
+		{"double quotes", `KEY="value"`, []dotenvKV{{"KEY", "value", 1}}},
+		{"double quotes with comment", `KEY="value # kept" # dropped`, []dotenvKV{{"KEY", "value # kept", 1}}},
+		{"escape sequences", `KEY="a\nb\tc\\d\"e\$f"`, []dotenvKV{{"KEY", "a\nb\tc\\d\"e$f", 1}}},
+		{"unknown escape", `KEY="a\qb"`, []dotenvKV{{"KEY", `a\qb`, 1}}},
+		{"empty quotes", `KEY=""`, []dotenvKV{{"KEY", "", 1}}},
+		{"multi-line double quotes", "KEY=\"line1\nline2\nline3\"\nNEXT=value", []dotenvKV{
+			{"KEY", "line1\nline2\nline3", 1},
+			{"NEXT", "value", 4},
+		}},
+		{"pem key", "CERT=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\n\"", []dotenvKV{{"CERT", "-----BEGIN KEY-----\nabc\n-----END KEY-----\n", 1}}},
+		{"escaped line break", "KEY=\"a\\\nb\"\nNEXT=1", []dotenvKV{{"KEY", "ab", 1}, {"NEXT", "1", 3}}},
+		{"crlf line endings", "A=1\r\nB=\"2\r\n3\"\r\n", []dotenvKV{{"A", "1", 1}, {"B", "2\n3", 2}}},
+		{"duplicate keys keep order", "A=1\nA=2", []dotenvKV{{"A", "1", 1}, {"A", "2", 2}}},
+		{"nested key", "NESTED_KEY=value", []dotenvKV{{"NESTED_KEY", "value", 1}}},
+	}
+
+	for _, tc := range corpus {
+		entries, err := parseDotenv(strings.NewReader(tc.input), "")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !slices.Equal(toDotenvKV(entries), tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, toDotenvKV(entries))
+		}
+	}
+}
+
+func TestParseDotenvInclude(t *testing.T) {
+	input := "A=1\ninclude other.env\ninclude? 'optional.env' # comment\ninclude=value\n"
+	entries, err := parseDotenv(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []dotenvEntry{
+		{key: "A", value: "1", line: 1, raw: "A=1"},
+		{value: "other.env", line: 2, raw: "include other.env", include: true},
+		{value: "optional.env", line: 3, raw: "include? 'optional.env' # comment", include: true, optional: true},
+		{key: "include", value: "value", line: 4, raw: "include=value"},
+	}
+	if !slices.Equal(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+}
+
+func TestParseDotenvInvalid(t *testing.T) {
+	corpus := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"missing separator", "KEY", 1},
+		{"missing key", "=value", 1},
+		{"whitespace in key", "MY KEY=value", 1},
+		{"unterminated double quote", "A=1\nKEY=\"value\nB=2", 2},
+		{"unterminated single quote", "KEY='value", 1},
+		{"garbage after quote", `KEY="value" garbage`, 1},
+	}
+
+	for _, tc := range corpus {
+		_, err := parseDotenv(strings.NewReader(tc.input), "")
+		var syntaxErr *ErrDotenvSyntax
+		var lineErr *ErrFileLine
+		if !errors.As(err, &syntaxErr) || !errors.As(err, &lineErr) {
+			t.Errorf("%s: expected syntax error, got %v", tc.name, err)
+			continue
+		}
+		if lineErr.line != tc.line {
+			t.Errorf("%s: expected error on line %d, got %d", tc.name, tc.line, lineErr.line)
+		}
+		if strings.Count(err.Error(), "line") != 1 {
+			t.Errorf("%s: expected the line to be reported once, got %v", tc.name, err)
+		}
+		if !errors.Is(err, ErrLoadingConfig) {
+			t.Errorf("%s: expected error to wrap ErrLoadingConfig", tc.name)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+const (
+	FORMAT_ENV  = "env"
+	FORMAT_JSON = "json"
+)
+
+// Format reads and writes a structured config format, see RegisterFormat
+type Format struct {
+	// Extensions are the file extensions of the format including the dot, e.g. ".yaml"
+	Extensions []string
+	// Parse reads nested maps and lists, leaves are handled like the values of WithInitialValues
+	Parse func(r io.Reader) (map[string]any, error)
+	// Dump writes nested maps and lists of string values
+	Dump func(w io.Writer, values any) error
+}
+
+var formats = struct {
+	mu         sync.RWMutex
+	registered map[string]Format
+}{registered: make(map[string]Format)}
+
+// RegisterFormat makes a format available to the loaders and Dump, usually from the init function
+// of a package like config/formats. The env and json formats are built in and can not be replaced.
+// Extensions are not added to CONF_DIR_EXTENSIONS.
+func RegisterFormat(name string, format Format) {
+	if name == FORMAT_ENV || name == FORMAT_JSON {
+		panic("config: format " + name + " is built in")
+	}
+	formats.mu.Lock()
+	defer formats.mu.Unlock()
+	formats.registered[name] = format
+}
+
+// Formats returns the names of the formats supported by the loaders and Dump
+func Formats() []string {
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+	names := make([]string, 0, len(formats.registered))
+	for name := range formats.registered {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return append([]string{FORMAT_ENV, FORMAT_JSON}, names...)
+}
+
+func lookupFormat(name string) (Format, bool) {
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+	format, ok := formats.registered[name]
+	return format, ok
+}
+
+// FormatFromPath returns the format of a config file by its extension, files without a known extension are env files
+func FormatFromPath(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		return FORMAT_JSON
+	}
+	formats.mu.RLock()
+	defer formats.mu.RUnlock()
+	for name, format := range formats.registered {
+		if slices.Contains(format.Extensions, ext) {
+			return name
+		}
+	}
+	return FORMAT_ENV
+}
+
+// Dump writes all values in the given format, see Formats.
+// The env format writes one KEY=value line per key, values with line breaks, comments, quotes or
+// surrounding white space are quoted and have to be loaded with ConfigLoader.Dotenv. The other formats write nested trees,
+// keys holding a value and nested keys at the same time can only be written as env.
+func (c *Config) Dump(ctx context.Context, w io.Writer, format string) error {
+	values, err := c.values(ctx, "")
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tree := keytree.Build("", values)
+	buffer := bufio.NewWriter(w)
+	switch format {
+	case FORMAT_ENV:
+		err = dumpEnv(buffer, tree)
+	case FORMAT_JSON:
+		err = dumpJSON(buffer, tree)
+	default:
+		registered, ok := lookupFormat(format)
+		if !ok || registered.Dump == nil {
+			return &ErrDumpFormat{format: format}
+		}
+		var nested any
+		if nested, err = nestedValue(tree); err == nil {
+			err = registered.Dump(buffer, nested)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+func dumpEnv(w *bufio.Writer, tree *keytree.Tree) error {
+	return walkTree(tree, func(key string, value string, hasValue bool) error {
+		if !hasValue {
+			return nil
+		}
+		_, err := w.WriteString(key + ENTRY_SPLIT + quoteEnvValue(value) + "\n")
+		return err
+	}, false)
+}
+
+// quoteEnvValue writes plain values as they are, values the line loader can not read back are
+// double quoted with the escapes of the dotenv loader, so they round-trip with ConfigLoader.Dotenv
+func quoteEnvValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, "\r\n\t#\"'`") && strings.TrimSpace(value) == value {
+		return value
+	}
+	builder := strings.Builder{}
+	builder.WriteByte('"')
+	for _, char := range value {
+		switch char {
+		case '"', '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(char)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+func dumpJSON(w *bufio.Writer, tree *keytree.Tree) error {
+	value, err := nestedValue(tree)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// nestedValue converts the tree into nested maps and lists, a node can't hold a value and children
+func nestedValue(t *keytree.Tree) (any, error) {
+	if t.HasValue && len(t.Children) > 0 {
+		return nil, &ErrDumpConflict{key: t.Key}
+	}
+	if t.HasValue {
+		return t.Value, nil
+	}
+	if t.IsList() {
+		list := make([]any, 0, len(t.Children))
+		for _, name := range t.ChildNames() {
+			value, err := nestedValue(t.Children[name])
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+	values := make(map[string]any, len(t.Children))
+	for name, child := range t.Children {
+		value, err := nestedValue(child)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newDumpTestConfig(t *testing.T) *Config {
+	config, err := WithInitialValues(context.TODO(), map[string]interface{}{
+		"name": "app",
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 8080,
+		},
+		"hosts": []interface{}{"alpha", "beta"},
+		"motd":  "hello\nworld",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestDumpEnv(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := newDumpTestConfig(t).Dump(context.TODO(), builder, FORMAT_ENV); err != nil {
+		t.Fatal(err)
+	}
+	expected := "HOSTS/0=alpha\nHOSTS/1=beta\nMOTD=\"hello\\nworld\"\nNAME=app\nSERVER/HOST=localhost\nSERVER/PORT=8080\n"
+	if builder.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, builder.String())
+	}
+}
+
+func TestDumpEnvRoundTrip(t *testing.T) {
+	ctx := context.TODO()
+	values := map[string]interface{}{
+		"comment":  "a # b",
+		"hash":     "#channel",
+		"padded":   "  padded\t",
+		"quoted":   `"quoted" and 'single'`,
+		"escapes":  `C:\path\n`,
+		"control":  "\x01\u00e9",
+		"lines":    "first\r\nsecond\n",
+		"backtick": "`cmd`",
+		"plain":    "value=with=equals",
+		"empty":    "",
+	}
+	config, err := WithInitialValues(ctx, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outFile := path.Join(t.TempDir(), "dump.env")
+	if err := config.DumpToFile(ctx, FORMAT_ENV, outFile); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&ConfigLoader{Dotenv: true}).LoadFile(ctx, loaded, []string{outFile}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(outFile)
+	if err := loaded.Compare(ctx, config, true); err != nil {
+		t.Errorf("%v in dump:\n%s", err, content)
+	}
+	if err := config.Compare(ctx, loaded, true); err != nil {
+		t.Errorf("%v in dump:\n%s", err, content)
+	}
+}
+
+func TestDumpFormats(t *testing.T) {
+	expected := map[string]string{
+		FORMAT_JSON: `{
+  "HOSTS": [
+    "alpha",
+    "beta"
+  ],
+  "MOTD": "hello\nworld",
+  "NAME": "app",
+  "SERVER": {
+    "HOST": "localhost",
+    "PORT": "8080"
+  }
+}
+`,
+	}
+	config := newDumpTestConfig(t)
+	for format, output := range expected {
+		builder := &strings.Builder{}
+		if err := config.Dump(context.TODO(), builder, format); err != nil {
+			t.Fatal(err)
+		}
+		if builder.String() != output {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", format, output, builder.String())
+		}
+	}
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	ctx := context.TODO()
+	config := newDumpTestConfig(t)
+	dir := t.TempDir()
+	for _, format := range []string{FORMAT_JSON} {
+		outFile := path.Join(dir, "config."+format)
+		if err := config.DumpToFile(ctx, format, outFile); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := NewLoadedConfig(ctx, nil, []string{outFile})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := loaded.Compare(ctx, config, true); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+		if err := config.Compare(ctx, loaded, true); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestDumpErrors(t *testing.T) {
+	config := newDumpTestConfig(t)
+	if err := config.Dump(context.TODO(), &strings.Builder{}, "xml"); !errors.Is(err, ErrDumpFailed) {
+		t.Errorf("Expected dump error, got %v", err)
+	}
+	if err := config.Set(context.TODO(), "SERVER", "value", false); err != nil {
+		t.Fatal(err)
+	}
+	var conflict *ErrDumpConflict
+	if err := config.Dump(context.TODO(), &strings.Builder{}, FORMAT_JSON); !errors.As(err, &conflict) {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+	if err := config.Dump(context.TODO(), &strings.Builder{}, FORMAT_ENV); err != nil {
+		t.Errorf("Expected env dump to succeed, got %v", err)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	formats := map[string]string{
+		"app.json":  FORMAT_JSON,
+		"app.JSON":  FORMAT_JSON,
+		"app.yaml":  FORMAT_ENV,
+		"app.env":   FORMAT_ENV,
+		"app.conf":  FORMAT_ENV,
+		"no_suffix": FORMAT_ENV,
+	}
+	for file, format := range formats {
+		if got := FormatFromPath(file); got != format {
+			t.Errorf("%s: expected %s, got %s", file, format, got)
+		}
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	ctx := context.TODO()
+	RegisterFormat("test", Format{
+		Extensions: []string{".test"},
+		Parse: func(r io.Reader) (map[string]any, error) {
+			content, err := io.ReadAll(r)
+			return map[string]any{"content": map[string]any{"value": string(content)}}, err
+		},
+		Dump: func(w io.Writer, values any) error {
+			_, err := fmt.Fprint(w, values)
+			return err
+		},
+	})
+	if !slices.Contains(Formats(), "test") || FormatFromPath("app.TEST") != "test" {
+		t.Errorf("Expected registered format, got %v", Formats())
+	}
+	file := path.Join(t.TempDir(), "app.test")
+	if err := os.WriteFile(file, []byte("raw"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	if err := config.Dump(ctx, builder, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != "map[CONTENT:map[VALUE:raw]]" {
+		t.Errorf("Unexpected dump %q", builder.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected built in format not to be replaced")
+		}
+	}()
+	RegisterFormat(FORMAT_JSON, Format{})
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SYSTEMD_COMMENT_CHAR starts comment lines in systemd environment files, next to DOTENV_COMMENT_CHAR
+const SYSTEMD_COMMENT_CHAR = ';'
+
+// EnvSource provides environment variables as NAME=value entries, like os.Environ.
+// It is called each time variables are loaded.
+type EnvSource func() ([]string, error)
+
+// OSEnv reads the environment of the current process
+func OSEnv() EnvSource {
+	return func() ([]string, error) {
+		return os.Environ(), nil
+	}
+}
+
+// EnvSnapshot provides a fixed list of entries, e.g. captured earlier with os.Environ
+func EnvSnapshot(environ []string) EnvSource {
+	snapshot := slices.Clone(environ)
+	return func() ([]string, error) {
+		return slices.Clone(snapshot), nil
+	}
+}
+
+// EnvMap provides the variables of a map, sorted by name
+func EnvMap(vars map[string]string) EnvSource {
+	environ := make([]string, 0, len(vars))
+	for name, value := range vars {
+		environ = append(environ, name+"="+value)
+	}
+	slices.Sort(environ)
+	return EnvSnapshot(environ)
+}
+
+// ProcEnv reads the environment another process was started with from /proc/<pid>/environ (Linux only)
+func ProcEnv(pid int) EnvSource {
+	path := "/proc/" + strconv.Itoa(pid) + "/environ"
+	return func() ([]string, error) {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, &ErrEnvSource{source: path, nested: err}
+		}
+		environ := []string{}
+		for _, entry := range bytes.Split(raw, []byte{0}) {
+			if len(entry) > 0 {
+				environ = append(environ, string(entry))
+			}
+		}
+		return environ, nil
+	}
+}
+
+// EnvironmentFile reads a systemd EnvironmentFile, lines starting with '#' or ';' are comments
+// and values may be quoted. Like in unit files, a path prefixed with '-' is optional.
+func EnvironmentFile(path string) EnvSource {
+	path, optional := strings.CutPrefix(path, "-")
+	return func() ([]string, error) {
+		raw, err := os.ReadFile(path)
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		if err != nil {
+			return nil, &ErrEnvSource{source: path, nested: err}
+		}
+		stripped, err := stripSystemdComments(raw)
+		if err != nil {
+			return nil, &ErrEnvSource{source: path, nested: err}
+		}
+		entries, err := parseDotenv(bytes.NewReader(stripped), path)
+		if err != nil {
+			return nil, &ErrEnvSource{source: path, nested: err}
+		}
+		environ := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.include {
+				return nil, &ErrEnvSource{source: path, nested: &ErrDotenvSyntax{line: entry.line, reason: "includes are not supported"}}
+			}
+			environ = append(environ, entry.key+"="+entry.value)
+		}
+		return environ, nil
+	}
+}
+
+// stripSystemdComments empties lines starting with ';', so the dotenv parser keeps the line numbers
+func stripSystemdComments(raw []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if trimmed := bytes.TrimLeft(line, " \t"); len(trimmed) > 0 && trimmed[0] == SYSTEMD_COMMENT_CHAR {
+			line = nil
+		}
+		buffer.Write(line)
+		buffer.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// lookupEnv returns the value of a variable of source, the process environment if source is nil
+func lookupEnv(source EnvSource, name string) (string, error) {
+	if source == nil {
+		return os.Getenv(name), nil
+	}
+	environ, err := source()
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range environ {
+		if value, ok := strings.CutPrefix(entry, name+"="); ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
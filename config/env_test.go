@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvSnapshot(t *testing.T) {
+	t.Parallel()
+	environ := []string{"SNAPTEST_A=1", "SNAPTEST_B=2"}
+	source := EnvSnapshot(environ)
+	environ[0] = "SNAPTEST_A=changed"
+	values, err := source()
+	if err != nil || !slices.Equal(values, []string{"SNAPTEST_A=1", "SNAPTEST_B=2"}) {
+		t.Errorf("Unexpected snapshot %v (%v)", values, err)
+	}
+
+	values, err = EnvMap(map[string]string{"B": "2", "A": "1"})()
+	if err != nil || !slices.Equal(values, []string{"A=1", "B=2"}) {
+		t.Errorf("Unexpected map source %v (%v)", values, err)
+	}
+}
+
+func TestLoadEnvSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := &ConfigLoader{Env: EnvMap(map[string]string{"SOURCETEST_DB_HOST": "db", "OTHER": "x"})}
+	if err := loader.LoadEnv(ctx, store, []string{"SOURCETEST"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(store.Keys(ctx), []string{"DB/HOST"}) {
+		t.Errorf("Unexpected keys %v", store.Keys(ctx))
+	}
+
+	errSource := errors.New("source failed")
+	loader.Env = func() ([]string, error) { return nil, errSource }
+	if err := loader.LoadEnv(ctx, store, []string{"SOURCETEST"}); !errors.Is(err, errSource) {
+		t.Errorf("Expected source error, got %v", err)
+	}
+}
+
+func TestProcEnv(t *testing.T) {
+	if _, err := os.Stat("/proc/self/environ"); err != nil {
+		t.Skip("no /proc filesystem")
+	}
+	t.Setenv("PROCTEST_VALUE", "1")
+	// the environ file holds the environment the process was started with
+	environ, err := ProcEnv(os.Getpid())()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(environ) == 0 || slices.Contains(environ, "PROCTEST_VALUE=1") {
+		t.Errorf("Unexpected environ %v", environ)
+	}
+	if _, err := ProcEnv(-1)(); !errors.Is(err, ErrLoadingConfig) {
+		t.Errorf("Expected loading error, got %v", err)
+	}
+}
+
+func TestEnvironmentFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.env": "# comment\n; systemd comment\nUNITTEST_HOST=localhost\nUNITTEST_NAME=\"my app\"\nUNITTEST_PATH='/a b'\n",
+	})
+	environ, err := EnvironmentFile(path.Join(dir, "app.env"))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"UNITTEST_HOST=localhost", "UNITTEST_NAME=my app", "UNITTEST_PATH=/a b"}
+	if !slices.Equal(environ, expected) {
+		t.Errorf("Expected %v, got %v", expected, environ)
+	}
+
+	if environ, err := EnvironmentFile("-" + path.Join(dir, "missing.env"))(); err != nil || len(environ) != 0 {
+		t.Errorf("Expected optional file to be skipped, got %v (%v)", environ, err)
+	}
+	if _, err := EnvironmentFile(path.Join(dir, "missing.env"))(); !errors.Is(err, ErrLoadingConfig) {
+		t.Errorf("Expected loading error, got %v", err)
+	}
+
+	// lines longer than the scanner buffer are reported instead of cutting the file short
+	writeTestFiles(t, dir, map[string]string{"long.env": "UNITTEST_LONG=" + strings.Repeat("x", bufio.MaxScanTokenSize) + "\n"})
+	if _, err := EnvironmentFile(path.Join(dir, "long.env"))(); !errors.Is(err, ErrLoadingConfig) {
+		t.Errorf("Expected too long error, got %v", err)
+	}
+}
+
+func TestWithEnvSource(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.env":      "NAME=file\n",
+		"app.prod.env": "LEVEL=warn\n",
+	})
+	source := EnvMap(map[string]string{"OPTTEST_NAME": "env", "OPTTEST_STAGE": "prod"})
+	config, err := NewLoadedConfig(ctx, []string{"OPTTEST"}, []string{path.Join(dir, "app.env")},
+		WithEnvSource(source), WithProfileEnv("OPTTEST_STAGE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "prod" {
+		t.Errorf("Expected profile from env source, got '%s'", config.Profile())
+	}
+	if err := config.CompareMap(ctx, map[string]string{"NAME": "env", "STAGE": "prod", "LEVEL": "warn"}, true); err != nil {
+		t.Error(err)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -14,6 +15,14 @@ var (
 	ErrNoConfigSource = errors.New("no config source provided")
 	ErrLoadingConfig  = errors.New("loading config failed")
 	ErrValueInvalid   = errors.New("value invalid")
+	ErrConfigReadOnly = errors.New("config is read-only")
+	ErrConfigVersion  = errors.New("config version error")
+	// ErrVersioningUnsupported is returned if the store does not keep a history
+	ErrVersioningUnsupported = errors.New("store does not support versioning")
+	// ErrProfileUnsupported is returned if a profile is selected for a loader that cannot apply it
+	ErrProfileUnsupported = errors.New("loader does not support profiles")
+	// ErrSkipTree is returned by a WalkFunc to skip the children of the current node
+	ErrSkipTree = errors.New("skip tree")
 )
 
 type ErrKeyValueInvalid struct {
@@ -37,6 +46,19 @@ func (v *ErrKeyValueInvalid) Unwrap() error {
 	return ErrValueInvalid
 }
 
+type ErrValueNotAllowed struct {
+	value   string
+	allowed []string
+}
+
+func (e *ErrValueNotAllowed) Error() string {
+	return "value '" + e.value + "' is not one of " + strings.Join(e.allowed, ", ")
+}
+
+func (e *ErrValueNotAllowed) Unwrap() error {
+	return ErrValueInvalid
+}
+
 type ErrKeyInStore struct {
 	key string
 }
@@ -113,6 +135,30 @@ func (c *ErrDumpToFile) Unwrap() error {
 	return ErrDumpFailed
 }
 
+type ErrDumpFormat struct {
+	format string
+}
+
+func (e *ErrDumpFormat) Error() string {
+	return "invalid or unknown dump format: " + e.format
+}
+
+func (e *ErrDumpFormat) Unwrap() error {
+	return ErrDumpFailed
+}
+
+type ErrDumpConflict struct {
+	key string
+}
+
+func (e *ErrDumpConflict) Error() string {
+	return "key holds a value and nested keys, it can only be dumped as env: " + e.key
+}
+
+func (e *ErrDumpConflict) Unwrap() error {
+	return ErrDumpFailed
+}
+
 type ErrFieldNotConfig struct {
 	key string
 }
@@ -185,6 +231,84 @@ func (f *ErrFieldNotDuration) Unwrap() error {
 	return ErrTypeMismatch
 }
 
+type ErrFieldType struct {
+	key      string
+	typeName string
+	nested   error
+}
+
+func (f *ErrFieldType) Error() string {
+	if f.nested != nil && f.nested != ErrTypeMismatch {
+		return "field is not a " + f.typeName + ": " + f.key + ": " + f.nested.Error()
+	}
+	return "field is not a " + f.typeName + ": " + f.key
+}
+
+func (f *ErrFieldType) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+type ErrSizeInvalid struct {
+	value  string
+	reason string
+}
+
+func (e *ErrSizeInvalid) Error() string {
+	if e.reason != "" {
+		return "invalid byte size: " + e.value + ": " + e.reason
+	}
+	return "invalid byte size: " + e.value
+}
+
+func (e *ErrSizeInvalid) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+type ErrPercentInvalid struct {
+	value  string
+	reason string
+}
+
+func (e *ErrPercentInvalid) Error() string {
+	if e.reason != "" {
+		return "invalid percentage: " + e.value + ": " + e.reason
+	}
+	return "invalid percentage: " + e.value
+}
+
+func (e *ErrPercentInvalid) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+type ErrDurationInvalid struct {
+	value  string
+	reason string
+}
+
+func (e *ErrDurationInvalid) Error() string {
+	if e.reason != "" {
+		return "invalid duration: " + e.value + ": " + e.reason
+	}
+	return "invalid duration: " + e.value
+}
+
+func (e *ErrDurationInvalid) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+type ErrAliasConflict struct {
+	alias string
+	key   string
+}
+
+func (e *ErrAliasConflict) Error() string {
+	return "alias " + e.alias + " conflicts with key " + e.key
+}
+
+func (e *ErrAliasConflict) Unwrap() error {
+	return ErrConfigKey
+}
+
 type ErrCopyConfigReason struct {
 	err error
 }
@@ -232,3 +356,189 @@ func (e *ErrParsingEnvVar) Error() string {
 func (e *ErrParsingEnvVar) Unwrap() error {
 	return ErrLoadingConfig
 }
+
+type ErrEnvSource struct {
+	source string
+	nested error
+}
+
+func (e *ErrEnvSource) Error() string {
+	return "reading environment from " + e.source + " failed: " + e.nested.Error()
+}
+
+func (e *ErrEnvSource) Unwrap() error {
+	return ErrLoadingConfig
+}
+
+type ErrDotenvSyntax struct {
+	line   int
+	reason string
+}
+
+func (e *ErrDotenvSyntax) Error() string {
+	// line is left out when the error is wrapped in an ErrFileLine
+	if e.line == 0 {
+		return "dotenv syntax error: " + e.reason
+	}
+	return fmt.Sprintf("dotenv syntax error on line %d: %s", e.line, e.reason)
+}
+
+func (e *ErrDotenvSyntax) Unwrap() error {
+	return ErrLoadingConfig
+}
+
+type ErrFileLine struct {
+	path   string
+	line   int
+	raw    string
+	nested error
+}
+
+func (e *ErrFileLine) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("line %d: %v: %q", e.line, e.nested, e.raw)
+	}
+	return fmt.Sprintf("%s:%d: %v: %q", e.path, e.line, e.nested, e.raw)
+}
+
+func (e *ErrFileLine) Unwrap() []error {
+	if e.nested != nil {
+		return []error{e.nested, ErrLoadingConfig}
+	}
+	return []error{ErrLoadingConfig}
+}
+
+type ErrSecretTooLarge struct {
+	path  string
+	limit int64
+}
+
+func (e *ErrSecretTooLarge) Error() string {
+	return fmt.Sprintf("secret file %s exceeds size limit of %d bytes", e.path, e.limit)
+}
+
+func (e *ErrSecretTooLarge) Unwrap() error {
+	return ErrLoadingConfig
+}
+
+type ErrIncludeCycle struct {
+	chain []string
+}
+
+func (e *ErrIncludeCycle) Error() string {
+	return "include cycle detected: " + strings.Join(e.chain, " -> ")
+}
+
+func (e *ErrIncludeCycle) Unwrap() error {
+	return ErrLoadingConfig
+}
+
+type ErrFileFormat struct {
+	format string
+}
+
+func (e *ErrFileFormat) Error() string {
+	return "invalid or unknown file format: " + e.format
+}
+
+func (e *ErrFileFormat) Unwrap() error {
+	return ErrLoadingConfig
+}
+
+type ErrComputedValue struct {
+	key    string
+	nested error
+}
+
+func (e *ErrComputedValue) Error() string {
+	return "computing value of " + e.key + " failed: " + e.nested.Error()
+}
+
+func (e *ErrComputedValue) Unwrap() error {
+	if e.nested != nil {
+		return e.nested
+	}
+	return ErrValueInvalid
+}
+
+type ErrKeyComputed struct {
+	key string
+}
+
+func (e *ErrKeyComputed) Error() string {
+	return "key is computed and can not be set: " + e.key
+}
+
+func (e *ErrKeyComputed) Unwrap() error {
+	return ErrConfigKey
+}
+
+type ErrComputeCycle struct {
+	chain []string
+}
+
+func (e *ErrComputeCycle) Error() string {
+	return "computed keys depend on each other: " + strings.Join(e.chain, " -> ")
+}
+
+func (e *ErrComputeCycle) Unwrap() error {
+	return ErrConfigKey
+}
+
+type ErrPatternInvalid struct {
+	pattern string
+	nested  error
+}
+
+func (e *ErrPatternInvalid) Error() string {
+	if e.nested != nil {
+		return "invalid key pattern: " + e.pattern + ": " + e.nested.Error()
+	}
+	return "invalid key pattern: " + e.pattern
+}
+
+func (e *ErrPatternInvalid) Unwrap() error {
+	return ErrConfigKey
+}
+
+type ErrSnapshotReadOnly struct {
+	key string
+}
+
+func (e *ErrSnapshotReadOnly) Error() string {
+	if e.key == "" {
+		return "snapshot is read-only"
+	}
+	return "snapshot is read-only, cannot modify key: " + e.key
+}
+
+func (e *ErrSnapshotReadOnly) Unwrap() error {
+	return ErrConfigReadOnly
+}
+
+type ErrConfigFrozen struct {
+	key string
+}
+
+func (e *ErrConfigFrozen) Error() string {
+	if e.key == "" {
+		return "config is frozen"
+	}
+	return "config is frozen, cannot modify key: " + e.key
+}
+
+func (e *ErrConfigFrozen) Unwrap() error {
+	return ErrConfigReadOnly
+}
+
+type ErrVersionNotFound struct {
+	version uint64
+}
+
+func (e *ErrVersionNotFound) Error() string {
+	return fmt.Sprintf("config version not available: %d", e.version)
+}
+
+func (e *ErrVersionNotFound) Unwrap() error {
+	return ErrConfigVersion
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// WithFallback layers store over fallback, keys missing in store are read from fallback, e.g. defaults.
+// Writes only change store, so a deleted key shows the value of fallback again.
+// Both stores stay live, changes to either of them are visible through the layer.
+func WithFallback(store ConfigStore, fallback ConfigStore) ConfigStore {
+	return &fallbackStore{store: store, fallback: fallback}
+}
+
+// fallbackStore reads the union of both stores, values of store take precedence
+type fallbackStore struct {
+	store    ConfigStore
+	fallback ConfigStore
+}
+
+func (f *fallbackStore) Get(ctx context.Context, key string) (string, error) {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return "", err
+	}
+	values := f.GetAll(ctx, key)
+	switch len(values) {
+	case 0:
+		return "", &ErrKeyNotFound{key: key}
+	case 1:
+		return values[""], nil
+	default:
+		return "", &ErrKeyAmbiguous{key: key}
+	}
+}
+
+func (f *fallbackStore) GetAll(ctx context.Context, key string) map[string]string {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	values := f.fallback.GetAll(ctx, key)
+	for suffix, value := range f.store.GetAll(ctx, key) {
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[suffix] = value
+	}
+	return values
+}
+
+func (f *fallbackStore) Set(ctx context.Context, key string, value string, force bool) error {
+	return f.store.Set(ctx, key, value, force)
+}
+
+func (f *fallbackStore) Delete(ctx context.Context, key string) error {
+	return f.store.Delete(ctx, key)
+}
+
+func (f *fallbackStore) DeletePrefix(ctx context.Context, prefix string) error {
+	return f.store.DeletePrefix(ctx, prefix)
+}
+
+func (f *fallbackStore) Has(ctx context.Context, key string) bool {
+	return f.store.Has(ctx, key) || f.fallback.Has(ctx, key)
+}
+
+func (f *fallbackStore) Keys(ctx context.Context) []string {
+	keys := append(f.store.Keys(ctx), f.fallback.Keys(ctx)...)
+	slices.SortFunc(keys, CompareKeys)
+	return slices.Compact(keys)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWithFallback(t *testing.T) {
+	ctx := context.TODO()
+	defaults, err := WithInitialValues(ctx, map[string]interface{}{"prefix": "service", "folder": "/var/log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := WithInitialValues(ctx, map[string]interface{}{"file": map[string]interface{}{"prefix": "app"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := WithFallback(config.View("FILE"), defaults)
+	if value, err := layer.Get(ctx, "prefix"); err != nil || value != "app" {
+		t.Errorf("Expected 'app', got '%s' (%v)", value, err)
+	}
+	if value, err := layer.Get(ctx, "FOLDER"); err != nil || value != "/var/log" {
+		t.Errorf("Expected default '/var/log', got '%s' (%v)", value, err)
+	}
+	if !slices.Equal(layer.Keys(ctx), []string{"FOLDER", "PREFIX"}) {
+		t.Errorf("Unexpected keys %v", layer.Keys(ctx))
+	}
+	if _, err := layer.Get(ctx, "MISSING"); !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected key not found, got %v", err)
+	}
+
+	// changes of the parent are seen through the layer
+	if err := config.Set(ctx, "FILE/FOLDER", "/tmp", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := layer.Get(ctx, "FOLDER"); err != nil || value != "/tmp" {
+		t.Errorf("Expected '/tmp', got '%s' (%v)", value, err)
+	}
+
+	// writes only reach the store, the defaults stay unchanged
+	if err := layer.Set(ctx, "SUFFIX", "log", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := layer.Delete(ctx, "FOLDER"); err != nil {
+		t.Fatal(err)
+	}
+	if !config.Has(ctx, "FILE/SUFFIX") || defaults.Has(ctx, "SUFFIX") {
+		t.Error("Expected the write to reach only the store")
+	}
+	if value, err := layer.Get(ctx, "FOLDER"); err != nil || value != "/var/log" {
+		t.Errorf("Expected the default after delete, got '%s' (%v)", value, err)
+	}
+}
@@ -0,0 +1,29 @@
+package formats
+
+import "github.com/myLogic207/gotils/config"
+
+type ErrSyntax struct {
+	format string
+	nested error
+}
+
+func (e *ErrSyntax) Error() string {
+	return e.format + " syntax error: " + e.nested.Error()
+}
+
+func (e *ErrSyntax) Unwrap() []error {
+	return []error{e.nested, config.ErrLoadingConfig}
+}
+
+type ErrEncode struct {
+	format string
+	nested error
+}
+
+func (e *ErrEncode) Error() string {
+	return "failed to write " + e.format + ": " + e.nested.Error()
+}
+
+func (e *ErrEncode) Unwrap() []error {
+	return []error{e.nested, config.ErrDumpFailed}
+}
@@ -0,0 +1,52 @@
+// Package formats adds YAML and TOML support to the config loaders and Dump.
+// Import it for its side effects:
+//
+//	import _ "github.com/myLogic207/gotils/config/formats"
+package formats
+
+import (
+	"fmt"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+const (
+	FORMAT_YAML = "yaml"
+	FORMAT_TOML = "toml"
+)
+
+func init() {
+	config.RegisterFormat(FORMAT_YAML, config.Format{Extensions: []string{".yaml", ".yml"}, Parse: parseYAML, Dump: dumpYAML})
+	config.RegisterFormat(FORMAT_TOML, config.Format{Extensions: []string{".toml"}, Parse: parseTOML, Dump: dumpTOML})
+}
+
+// normalize converts the maps and lists returned by the decoders into the
+// map[string]any and []any values read by the loaders
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			v[key] = normalize(val)
+		}
+		return v
+	case map[any]any:
+		values := make(map[string]any, len(v))
+		for key, val := range v {
+			values[fmt.Sprint(key)] = normalize(val)
+		}
+		return values
+	case []any:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+	case []map[string]any:
+		list := make([]any, 0, len(v))
+		for _, val := range v {
+			list = append(list, normalize(val))
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -0,0 +1,26 @@
+package formats
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML reads a TOML document, arrays of tables are read as lists
+func parseTOML(r io.Reader) (map[string]any, error) {
+	values := map[string]any{}
+	if _, err := toml.NewDecoder(r).Decode(&values); err != nil {
+		return nil, &ErrSyntax{format: FORMAT_TOML, nested: err}
+	}
+	return normalize(values).(map[string]any), nil
+}
+
+// dumpTOML writes values as a TOML document, the top level has to be a table
+func dumpTOML(w io.Writer, values any) error {
+	encoder := toml.NewEncoder(w)
+	encoder.Indent = ""
+	if err := encoder.Encode(values); err != nil {
+		return &ErrEncode{format: FORMAT_TOML, nested: err}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package formats
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+func TestLoadTOML(t *testing.T) {
+	ctx := context.TODO()
+	file := writeFile(t, t.TempDir(), "app.toml", `# comment
+name = "app"
+count = 1_000
+ratio = 0.5
+enabled = true
+point = { x = 1, y = "two" }
+ports = [8080, 8081]
+
+[server.tls]
+cert = "cert.pem"
+
+[[users]]
+name = "admin"
+
+[[users]]
+name = "guest"
+`)
+	cfg, err := config.NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"NAME":            "app",
+		"COUNT":           "1000",
+		"RATIO":           "0.5",
+		"ENABLED":         "true",
+		"POINT/X":         "1",
+		"POINT/Y":         "two",
+		"PORTS/0":         "8080",
+		"PORTS/1":         "8081",
+		"SERVER/TLS/CERT": "cert.pem",
+		"USERS/0/NAME":    "admin",
+		"USERS/1/NAME":    "guest",
+	}
+	if err := cfg.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadTOMLErrors(t *testing.T) {
+	documents := map[string]string{
+		"missing equals": "key\n",
+		"duplicate key":  "a = 1\na = 2\n",
+		"invalid value":  "a = nope\n",
+		"open table":     "[table\n",
+	}
+	for name, document := range documents {
+		store, err := config.NewConfigStore(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = (&config.ConfigLoader{}).LoadReader(context.TODO(), store, strings.NewReader(document), FORMAT_TOML)
+		var syntaxErr *ErrSyntax
+		if !errors.As(err, &syntaxErr) || !errors.Is(err, config.ErrLoadingConfig) {
+			t.Errorf("%s: expected syntax error, got %v", name, err)
+		}
+	}
+}
+
+func TestDumpTOML(t *testing.T) {
+	ctx := context.TODO()
+	cfg, err := config.WithInitialValues(ctx, map[string]interface{}{
+		"name":   "app",
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"hosts":  []interface{}{"alpha", "beta"},
+		"users":  []interface{}{map[string]interface{}{"name": "admin"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	if err := cfg.Dump(ctx, builder, FORMAT_TOML); err != nil {
+		t.Fatal(err)
+	}
+	file := writeFile(t, t.TempDir(), "dump.toml", builder.String())
+	loaded, err := config.NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Compare(ctx, loaded, true); err != nil {
+		t.Errorf("%v in dump:\n%s", err, builder.String())
+	}
+	if err := loaded.Compare(ctx, cfg, true); err != nil {
+		t.Errorf("%v in dump:\n%s", err, builder.String())
+	}
+
+	if !slices.Equal(config.Formats(), []string{config.FORMAT_ENV, config.FORMAT_JSON, FORMAT_TOML, FORMAT_YAML}) {
+		t.Errorf("Unexpected formats %v", config.Formats())
+	}
+	if format := config.FormatFromPath("app.TOML"); format != FORMAT_TOML {
+		t.Errorf("Expected toml format, got %s", format)
+	}
+}
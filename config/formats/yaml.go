@@ -0,0 +1,26 @@
+package formats
+
+import (
+	"errors"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML reads a single YAML document, an empty document has no values
+func parseYAML(r io.Reader) (map[string]any, error) {
+	values := map[string]any{}
+	if err := yaml.NewDecoder(r).Decode(&values); err != nil && !errors.Is(err, io.EOF) {
+		return nil, &ErrSyntax{format: FORMAT_YAML, nested: err}
+	}
+	return normalize(values).(map[string]any), nil
+}
+
+func dumpYAML(w io.Writer, values any) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(values); err != nil {
+		return &ErrEncode{format: FORMAT_YAML, nested: err}
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,122 @@
+package formats
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	file := path.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadYAML(t *testing.T) {
+	ctx := context.TODO()
+	file := writeFile(t, t.TempDir(), "app.yml", `---
+name: app # comment
+empty:
+server: {host: localhost, port: 8080}
+hosts: [alpha, beta]
+users:
+  - name: admin
+  - name: guest
+text: |
+  line one
+  line two
+1: numeric key
+`)
+	cfg, err := config.NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"NAME":         "app",
+		"SERVER/HOST":  "localhost",
+		"SERVER/PORT":  "8080",
+		"HOSTS/0":      "alpha",
+		"HOSTS/1":      "beta",
+		"USERS/0/NAME": "admin",
+		"USERS/1/NAME": "guest",
+		"TEXT":         "line one\nline two\n",
+		"1":            "numeric key",
+	}
+	if err := cfg.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+	if cfg.Has(ctx, "EMPTY") {
+		t.Error("Expected null value to be unset")
+	}
+}
+
+func TestLoadYAMLErrors(t *testing.T) {
+	documents := map[string]string{
+		"bad indentation": "a:\n  b: 1\n c: 2\n",
+		"duplicate key":   "a: 1\na: 2\n",
+		"no mapping":      "just text\n",
+		"unterminated":    "a: \"open\n",
+	}
+	for name, document := range documents {
+		store, err := config.NewConfigStore(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = (&config.ConfigLoader{}).LoadReader(context.TODO(), store, strings.NewReader(document), FORMAT_YAML)
+		var syntaxErr *ErrSyntax
+		if !errors.As(err, &syntaxErr) || !errors.Is(err, config.ErrLoadingConfig) {
+			t.Errorf("%s: expected syntax error, got %v", name, err)
+		}
+	}
+}
+
+func TestDumpYAML(t *testing.T) {
+	ctx := context.TODO()
+	cfg, err := config.WithInitialValues(ctx, map[string]interface{}{
+		"name":   "app",
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"hosts":  []interface{}{"alpha", "beta"},
+		"motd":   "hello\nworld",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	if err := cfg.Dump(ctx, builder, FORMAT_YAML); err != nil {
+		t.Fatal(err)
+	}
+	expected := `HOSTS:
+  - alpha
+  - beta
+MOTD: |-
+  hello
+  world
+NAME: app
+SERVER:
+  HOST: localhost
+  PORT: "8080"
+`
+	if builder.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, builder.String())
+	}
+
+	file := writeFile(t, t.TempDir(), "dump.yaml", builder.String())
+	loaded, err := config.NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Compare(ctx, cfg, true); err != nil {
+		t.Error(err)
+	}
+	if err := cfg.Compare(ctx, loaded, true); err != nil {
+		t.Error(err)
+	}
+}
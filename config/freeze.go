@@ -0,0 +1,28 @@
+package config
+
+// Freeze returns a read-only copy of the config.
+// The frozen config reads from an immutable snapshot without locking, all writes return ErrConfigFrozen.
+// Aliases and computed keys can not be registered on the frozen config, so their copies are read without locking as well.
+func (c *Config) Freeze() *Config {
+	frozen := c.Snapshot()
+	frozen.frozen = true
+	if frozen.aliases != nil {
+		frozen.aliases.frozen = true
+	}
+	if frozen.computed != nil {
+		frozen.computed.frozen = true
+	}
+	return frozen
+}
+
+// Frozen reports if the config was created by Freeze
+func (c *Config) Frozen() bool {
+	return c.frozen
+}
+
+func (c *Config) checkFrozen(key string) error {
+	if c.frozen {
+		return &ErrConfigFrozen{key: key}
+	}
+	return nil
+}
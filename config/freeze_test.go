@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFreeze(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1", "nested": map[string]interface{}{"b": "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frozen := config.Freeze()
+	if !frozen.Frozen() || config.Frozen() {
+		t.Error("Only the returned config should be frozen")
+	}
+
+	var frozenErr *ErrConfigFrozen
+	if err := frozen.Set(ctx, "A", "2", true); !errors.As(err, &frozenErr) || !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.Merge(ctx, config, true); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.MergeIn(ctx, "OTHER", config, true); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.Delete(ctx, "A"); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.DeletePrefix(ctx, "NESTED"); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.View("NESTED").Set(ctx, "C", "3", false); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error through view, got %v", err)
+	}
+	if err := frozen.Update(ctx, func(tx Tx) error { return nil }); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+
+	// the frozen config does not see later writes
+	if err := config.Set(ctx, "A", "changed", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := frozen.CompareMap(ctx, map[string]string{"A": "1", "NESTED/B": "2"}, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFreezeConcurrentReads(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frozen := config.Freeze()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if value, err := frozen.Get(ctx, "A"); err != nil || value != "1" {
+					t.Errorf("Expected '1', got '%s' (%v)", value, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		_ = config.Set(ctx, "A", "other", true)
+	}
+	wg.Wait()
+}
+
+func TestFreezeRegistries(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"name": "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("NAME", "TITLE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Compute("GREETING", func(ctx context.Context, config *Config) (string, error) {
+		name, err := config.Get(ctx, "TITLE")
+		return "hello " + name, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	frozen := config.Freeze()
+	var frozenErr *ErrConfigFrozen
+	if err := frozen.Alias("NAME", "OTHER"); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+	if err := frozen.Compute("OTHER", nil); !errors.As(err, &frozenErr) {
+		t.Errorf("Expected frozen error, got %v", err)
+	}
+
+	// reads of the frozen config do not take the registry locks
+	frozen.aliases.mu.Lock()
+	frozen.computed.mu.Lock()
+	defer frozen.aliases.mu.Unlock()
+	defer frozen.computed.mu.Unlock()
+	done := make(chan string)
+	go func() {
+		value, _ := frozen.Get(ctx, "GREETING")
+		done <- value
+	}()
+	select {
+	case value := <-done:
+		if value != "hello app" {
+			t.Errorf("Expected 'hello app', got '%s'", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reading the frozen config waits for the registry locks")
+	}
+}
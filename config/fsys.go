@@ -0,0 +1,89 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileSource is the file system config files, includes and file references are read from
+type fileSource interface {
+	open(name string) (fs.File, error)
+	stat(name string) (fs.FileInfo, error)
+	readDir(name string) ([]fs.DirEntry, error)
+	// abs returns the cleaned absolute form of name, used to detect include cycles
+	abs(name string) (string, error)
+	isAbs(name string) bool
+	join(elem ...string) string
+	dir(name string) string
+}
+
+// osFiles reads from the operating system
+type osFiles struct{}
+
+func (osFiles) open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFiles) stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFiles) readDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFiles) abs(name string) (string, error) {
+	return filepath.Abs(name)
+}
+
+func (osFiles) isAbs(name string) bool {
+	return filepath.IsAbs(name)
+}
+
+func (osFiles) join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (osFiles) dir(name string) string {
+	return filepath.Dir(name)
+}
+
+// fsFiles reads from an fs.FS, paths are slash separated and a leading slash refers to the root of the FS
+type fsFiles struct {
+	fsys fs.FS
+}
+
+func (f fsFiles) open(name string) (fs.File, error) {
+	return f.fsys.Open(name)
+}
+
+func (f fsFiles) stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(f.fsys, name)
+}
+
+func (f fsFiles) readDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, name)
+}
+
+func (f fsFiles) abs(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "/"))
+	if !fs.ValidPath(cleaned) {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return cleaned, nil
+}
+
+func (f fsFiles) isAbs(name string) bool {
+	return strings.HasPrefix(name, "/")
+}
+
+func (f fsFiles) join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (f fsFiles) dir(name string) string {
+	return path.Dir(name)
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFS(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	fsys := fstest.MapFS{
+		"app.conf":             {Data: []byte("A=main\ninclude base/base.conf\nB=main\nTOKEN_FILE=secrets/token\n")},
+		"app.prod.conf":        {Data: []byte("B=prod\n")},
+		"base/base.conf":       {Data: []byte("A=base\nC=base\ninclude /conf.d\n")},
+		"conf.d/10-db.json":    {Data: []byte(`{"db": {"host": "localhost"}}`)},
+		"conf.d/20-cache.conf": {Data: []byte("CACHE_SIZE=10\n")},
+		"conf.d/30-app.yaml":   {Data: []byte("name: ignored\n")},
+		"conf.d/.hidden.conf":  {Data: []byte("HIDDEN=1\n")},
+		"secrets/token":        {Data: []byte("s3cret\n")},
+	}
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := &ConfigLoader{Profile: "prod"}
+	if err := loader.LoadFS(ctx, store, fsys, []string{"app.conf"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"A":          "base",
+		"B":          "prod",
+		"C":          "base",
+		"DB/HOST":    "localhost",
+		"CACHE/SIZE": "10",
+		"TOKEN":      "s3cret",
+	}
+	if !maps.Equal(store.(*ConfigStoreImpl).store, expected) {
+		t.Errorf("Unexpected store %q", store.(*ConfigStoreImpl).store)
+	}
+
+	if err := loader.LoadFS(ctx, store, fsys, []string{"missing.conf"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected missing file error, got %v", err)
+	}
+	if err := loader.LoadFS(ctx, store, fsys, []string{"../app.conf"}); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Expected invalid path error, got %v", err)
+	}
+}
+
+func TestLoadFSIncludeCycle(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"a.conf":     {Data: []byte("include sub/b.conf\n")},
+		"sub/b.conf": {Data: []byte("include ../a.conf\n")},
+	}
+	store, err := NewConfigStore(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cycleErr *ErrIncludeCycle
+	if err := (&ConfigLoader{}).LoadFS(context.TODO(), store, fsys, []string{"a.conf"}); !errors.As(err, &cycleErr) {
+		t.Errorf("Expected include cycle error, got %v", err)
+	}
+}
+
+func TestLoadReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	tests := []struct {
+		format string
+		dotenv bool
+		input  string
+	}{
+		{FORMAT_ENV, false, "NAME=app\nPROFILES_PROD_NAME=prod app\n"},
+		{FORMAT_ENV, true, "export NAME=\"app\"\nPROFILES_PROD_NAME='prod app'\n"},
+		{FORMAT_JSON, false, `{"name": "app", "profiles": {"prod": {"name": "prod app"}}}`},
+	}
+	for _, test := range tests {
+		store, err := NewConfigStore(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loader := &ConfigLoader{Dotenv: test.dotenv, Profile: "prod"}
+		if err := loader.LoadReader(ctx, store, strings.NewReader(test.input), test.format); err != nil {
+			t.Errorf("Loading %s (dotenv %t) failed: %v", test.format, test.dotenv, err)
+			continue
+		}
+		if value, err := store.Get(ctx, "NAME"); err != nil || value != "prod app" {
+			t.Errorf("Unexpected value for %s (dotenv %t): '%s' (%v)", test.format, test.dotenv, value, err)
+		}
+	}
+
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&ConfigLoader{}).LoadReader(ctx, store, strings.NewReader("{}"), "xml"); !errors.Is(err, ErrLoadingConfig) {
+		t.Errorf("Expected loading error for unknown format, got %v", err)
+	}
+}
+
+func TestLoadFSResolvePath(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	fsys := fstest.MapFS{
+		"conf/app.conf": {Data: []byte("DATA_DIR=data\nLOG_DIR=/logs\n")},
+	}
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("*/DIR", ResolvePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&ConfigLoader{}).LoadFS(ctx, config, fsys, []string{"conf/app.conf"}); err != nil {
+		t.Fatal(err)
+	}
+	// paths stay relative to the root of the fs, not the working directory
+	expected := map[string]string{
+		"DATA/DIR": "conf/data",
+		"LOG/DIR":  "/logs",
+	}
+	if err := config.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package keytree builds trees of flattened config keys, it is shared by config and its sub packages
+package keytree
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SEPARATOR separates the segments of a key, see config.CONFIG_TREE_SEPARATOR
+const SEPARATOR = "/"
+
+// Tree is a key of a config, keys with children are objects or lists
+type Tree struct {
+	Key      string
+	Value    string
+	HasValue bool
+	Children map[string]*Tree
+}
+
+// Build reads values indexed by their full key into a tree rooted at prefix
+func Build(prefix string, values map[string]string) *Tree {
+	root := &Tree{Key: prefix, Children: make(map[string]*Tree)}
+	for key, value := range values {
+		node := root
+		if rest, _ := CutPrefix(key, prefix); rest != "" {
+			for _, segment := range strings.Split(rest, SEPARATOR) {
+				child, ok := node.Children[segment]
+				if !ok {
+					child = &Tree{Key: Join(node.Key, segment), Children: make(map[string]*Tree)}
+					node.Children[segment] = child
+				}
+				node = child
+			}
+		}
+		node.Value = value
+		node.HasValue = true
+	}
+	return root
+}
+
+// ChildNames returns the names of the children, list indices are sorted numerically
+func (t *Tree) ChildNames() []string {
+	names := make([]string, 0, len(t.Children))
+	for name := range t.Children {
+		names = append(names, name)
+	}
+	if t.IsList() {
+		slices.SortFunc(names, func(a, b string) int {
+			indexA, _ := strconv.Atoi(a)
+			indexB, _ := strconv.Atoi(b)
+			return indexA - indexB
+		})
+	} else {
+		slices.Sort(names)
+	}
+	return names
+}
+
+// IsList checks if the children are indexed 0 to n-1, like flattened lists
+func (t *Tree) IsList() bool {
+	if len(t.Children) == 0 {
+		return false
+	}
+	for i := 0; i < len(t.Children); i++ {
+		if _, ok := t.Children[strconv.Itoa(i)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CutPrefix returns the part of key below prefix and whether key is at or below prefix
+func CutPrefix(key string, prefix string) (string, bool) {
+	if prefix == "" {
+		return key, true
+	}
+	if key == prefix {
+		return "", true
+	}
+	return strings.CutPrefix(key, prefix+SEPARATOR)
+}
+
+// Join appends key to base, either may be empty
+func Join(base string, key string) string {
+	if base == "" {
+		return key
+	}
+	if key == "" {
+		return base
+	}
+	return base + SEPARATOR + key
+}
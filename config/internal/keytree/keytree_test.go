@@ -0,0 +1,52 @@
+package keytree
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	tree := Build("APP", map[string]string{
+		"APP":         "root",
+		"APP/LIST/0":  "a",
+		"APP/LIST/10": "k",
+		"APP/NAME":    "app",
+	})
+	for i := 1; i < 10; i++ {
+		tree.Children["LIST"].Children[string(rune('0'+i))] = &Tree{}
+	}
+	if !tree.HasValue || tree.Value != "root" {
+		t.Errorf("Expected root value, got '%s'", tree.Value)
+	}
+	if !slices.Equal(tree.ChildNames(), []string{"LIST", "NAME"}) || tree.IsList() {
+		t.Errorf("Unexpected children %v", tree.ChildNames())
+	}
+	list := tree.Children["LIST"]
+	if !list.IsList() || list.ChildNames()[10] != "10" {
+		t.Errorf("Expected numerically sorted list, got %v", list.ChildNames())
+	}
+	if list.Key != "APP/LIST" || list.Children["10"].Key != "APP/LIST/10" {
+		t.Errorf("Unexpected keys %s and %s", list.Key, list.Children["10"].Key)
+	}
+}
+
+func TestCutPrefix(t *testing.T) {
+	for _, test := range []struct {
+		key    string
+		prefix string
+		rest   string
+		ok     bool
+	}{
+		{"DB/PORT", "", "DB/PORT", true},
+		{"DB", "DB", "", true},
+		{"DB/PORT", "DB", "PORT", true},
+		{"DBX/PORT", "DB", "DBX/PORT", false},
+	} {
+		if rest, ok := CutPrefix(test.key, test.prefix); rest != test.rest || ok != test.ok {
+			t.Errorf("Expected '%s' %t for %s below %s, got '%s' %t", test.rest, test.ok, test.key, test.prefix, rest, ok)
+		}
+	}
+	if Join("", "A") != "A" || Join("A", "") != "A" || Join("A", "B") != "A/B" {
+		t.Error("Unexpected joined keys")
+	}
+}
@@ -2,106 +2,480 @@ package config
 
 import (
 	"bufio"
-	"bytes"
 	"context"
-	"os"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	ENV_SPLIT_CHAR = "_"
-	ENTRY_SPLIT    = "="
+	ENV_SPLIT_CHAR             = "_"
+	ENTRY_SPLIT                = "="
+	INCLUDE_DIRECTIVE          = "include"
+	INCLUDE_OPTIONAL_DIRECTIVE = "include?"
 )
 
+// CONF_DIR_EXTENSIONS are the file extensions loaded from config directories
+var CONF_DIR_EXTENSIONS = []string{".conf", ".env", ".json"}
+
 type Loader interface {
 	LoadEnv(ctx context.Context, store ConfigStore, prefixList []string) error
 	LoadFile(ctx context.Context, store ConfigStore, paths []string) error
 }
 
-type ConfigLoader struct{}
+type ConfigLoader struct {
+	// Dotenv enables dotenv compatible parsing of config files,
+	// supporting quoted and multi-line values, export prefixes and inline comments
+	Dotenv bool
+	// Profile applies profile overlays, loading sibling files like app.<profile>.env
+	// after each file and lifting the PROFILES/<profile> subtree to the top level
+	Profile string
+	// Env provides the variables read by LoadEnv, the process environment if nil
+	Env EnvSource
+}
 
 func (cl *ConfigLoader) LoadEnv(ctx context.Context, store ConfigStore, prefixList []string) error {
-	eg, eCtx := errgroup.WithContext(ctx)
+	source := cl.Env
+	if source == nil {
+		source = OSEnv()
+	}
+	environ, err := source()
+	if err != nil {
+		return err
+	}
+	return cl.LoadEnviron(ctx, store, environ, prefixList)
+}
 
-	for _, envVar := range os.Environ() {
-		ev := envVar
+// LoadEnviron loads variables from environ instead of the process environment,
+// entries have the form NAME=value like the ones returned by os.Environ.
+// Prefixes are applied in the order of prefixList, so earlier prefixes take precedence.
+func (cl *ConfigLoader) LoadEnviron(ctx context.Context, store ConfigStore, environ []string, prefixList []string) error {
+	// variables are parsed concurrently, as file references are read while parsing
+	entries := make([]envEntry, len(environ))
+	eg := &errgroup.Group{}
+	for i, envVar := range environ {
+		index, ev := i, envVar
 		eg.Go(func() error {
-			key, val, err := parseEnvVar(ev, prefixList)
+			prefix, key, val, err := parseEnvVar(ev, prefixList)
 			if err != nil {
 				return &ErrParsingEnvVar{err}
 			}
-			return store.Set(eCtx, key, val, false)
+			entries[index] = envEntry{prefix: prefix, key: key, value: val}
+			return nil
 		})
 	}
-
 	if err := eg.Wait(); err != nil {
 		return err
 	}
 
+	for prefix := range prefixList {
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if entry.key == "" || entry.prefix != prefix {
+				// no matching prefix, or applied with its own prefix
+				continue
+			}
+			if err := setIfAbsent(ctx, store, entry.key, entry.value); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func parseEnvVar(envVar string, prefixList []string) (string, string, error) {
-	var foundPrefix string
-	for _, prefix := range prefixList {
+// envEntry is a parsed variable, prefix is the index of its prefix in the prefix list
+type envEntry struct {
+	prefix int
+	key    string
+	value  string
+}
+
+// parseEnvVar returns the index of the first matching prefix with the key and value, or -1 if no prefix matches
+func parseEnvVar(envVar string, prefixList []string) (int, string, string, error) {
+	for index, prefix := range prefixList {
 		if !strings.HasPrefix(envVar, prefix) {
 			continue
-		} else {
-			// matching prefix found
-			foundPrefix = prefix
-			break
 		}
+		// matching prefix found
+		key, value, err := handleEntry(osFiles{}, strings.TrimPrefix(envVar, prefix+ENV_SPLIT_CHAR), ENTRY_SPLIT)
+		return index, key, value, err
 	}
-	if foundPrefix == "" {
-		return "", "", nil
-	}
-	return handleEntry(strings.TrimPrefix(envVar, foundPrefix+ENV_SPLIT_CHAR), ENTRY_SPLIT)
+	return -1, "", "", nil
 }
 
+// LoadFile loads the given files in order, later files override values of earlier ones.
+// A path may point to a conf.d style directory, in which case all config files inside
+// are loaded in lexical order. Profile files like 10-app.prod.env are only loaded for
+// their profile, right after their base file. Files may include other files with 'include <path>',
+// or 'include? <path>' if the included file is optional. Relative include paths are
+// resolved against the directory of the including file.
+// Invalid lines are reported together as ErrFileLine errors, nothing is loaded in that case.
 func (cl *ConfigLoader) LoadFile(ctx context.Context, store ConfigStore, filePaths []string) error {
-	eg, eCtx := errgroup.WithContext(ctx)
+	return cl.loadFiles(ctx, store, osFiles{}, filePaths)
+}
+
+// LoadFS loads files from fsys like LoadFile does from the OS, e.g. configs embedded with go:embed.
+// Paths are slash separated and relative to the root of fsys, includes and profile siblings
+// and KEY_FILE references are read from fsys as well.
+func (cl *ConfigLoader) LoadFS(ctx context.Context, store ConfigStore, fsys fs.FS, filePaths []string) error {
+	return cl.loadFiles(ctx, store, fsFiles{fsys: fsys}, filePaths)
+}
+
+// LoadReader loads a single config of the given format (see Formats) from r.
+// There is no file to derive profile siblings from, but the PROFILES subtree is applied.
+// Relative includes in env files are resolved against the working directory.
+func (cl *ConfigLoader) LoadReader(ctx context.Context, store ConfigStore, r io.Reader, format string) error {
+	if !slices.Contains(Formats(), format) {
+		return &ErrFileFormat{format: format}
+	}
+	entries, err := cl.readFile(ctx, osFiles{}, r, "", format, nil)
+	if err != nil {
+		return err
+	}
+	if cl.Profile != "" {
+		entries = applyProfile(entries, cl.Profile)
+	}
+	return applyEntries(ctx, store, osFiles{}, entries)
+}
+
+func (cl *ConfigLoader) loadFiles(ctx context.Context, store ConfigStore, files fileSource, filePaths []string) error {
+	entries := []fileEntry{}
 	for _, filePath := range filePaths {
-		fp := filePath
-		eg.Go(func() error {
-			return cl.loadFile(eCtx, eg, fp, store)
-		})
+		fileEntries, err := cl.readPath(ctx, files, filePath, nil)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fileEntries...)
+		if cl.Profile == "" {
+			continue
+		}
+		profileEntries, err := cl.readProfileSibling(ctx, files, filePath)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, profileEntries...)
 	}
-	if err := eg.Wait(); err != nil {
+	if cl.Profile != "" {
+		entries = applyProfile(entries, cl.Profile)
+	}
+	return applyEntries(ctx, store, files, entries)
+}
+
+type fileEntry struct {
+	key   string
+	value string
+	path  string
+	line  int
+}
+
+// applyEntries writes entries read from files to the store, later entries override earlier ones with the same key
+func applyEntries(ctx context.Context, store ConfigStore, files fileSource, entries []fileEntry) error {
+	latest := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		latest[canonicalKey(store, entry.key)] = i
+	}
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if latest[canonicalKey(store, entry.key)] != i {
+			continue
+		}
+		// set hooks can resolve values against the file, see SourceFile
+		if err := setIfAbsent(withSourceFile(ctx, files, entry.path), store, entry.key, entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// canonicalKey returns the key entries are deduplicated by, so an alias and its key count as the same key
+func canonicalKey(store ConfigStore, key string) string {
+	if config, ok := store.(*Config); ok {
+		key = config.resolveKey(key)
+	}
+	return strings.ToUpper(strings.TrimSpace(key))
+}
+
+// setIfAbsent sets a value without overwriting, values already in the store take precedence
+func setIfAbsent(ctx context.Context, store ConfigStore, key string, value string) error {
+	var inStore *ErrKeyInStore
+	if err := store.Set(ctx, key, value, false); err != nil && !errors.As(err, &inStore) {
 		return err
 	}
 	return nil
 }
 
-func (cl *ConfigLoader) loadFile(ctx context.Context, errGroup *errgroup.Group, filePath string, store ConfigStore) error {
-	file, err := os.Open(filePath)
+// readPath reads a file or directory, includeChain holds the paths currently being read
+func (cl *ConfigLoader) readPath(ctx context.Context, files fileSource, filePath string, includeChain []string) ([]fileEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	absPath, err := files.abs(filePath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if slices.Contains(includeChain, absPath) {
+		return nil, &ErrIncludeCycle{chain: append(includeChain, absPath)}
+	}
+	includeChain = append(slices.Clone(includeChain), absPath)
+
+	info, err := files.stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return cl.readDir(ctx, files, absPath, includeChain)
+	}
+
+	file, err := files.open(absPath)
+	if err != nil {
+		return nil, err
 	}
 	defer file.Close()
+	return cl.readFile(ctx, files, file, absPath, FormatFromPath(absPath), includeChain)
+}
+
+// readFile parses the content of a single file, includes are resolved through files
+func (cl *ConfigLoader) readFile(ctx context.Context, files fileSource, file io.Reader, filePath string, format string, includeChain []string) ([]fileEntry, error) {
+	switch format {
+	case FORMAT_JSON:
+		return readJSON(file, filePath)
+	case FORMAT_ENV:
+	default:
+		registered, ok := lookupFormat(format)
+		if !ok || registered.Parse == nil {
+			return nil, &ErrFileFormat{format: format}
+		}
+		values, err := registered.Parse(file)
+		if err != nil {
+			return nil, err
+		}
+		return entriesFromValues(values, filePath)
+	}
+	switch {
+	case cl.Dotenv:
+		return cl.readDotenv(ctx, files, file, filePath, includeChain)
+	default:
+		return cl.readLines(ctx, files, file, filePath, includeChain)
+	}
+}
+
+func (cl *ConfigLoader) readDir(ctx context.Context, files fileSource, dir string, includeChain []string) ([]fileEntry, error) {
+	dirEntries, err := files.readDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// ReadDir returns the entries sorted by name
+	names := []string{}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || strings.HasPrefix(name, ".") || !slices.Contains(CONF_DIR_EXTENSIONS, filepath.Ext(name)) {
+			continue
+		}
+		names = append(names, name)
+	}
+	entries := []fileEntry{}
+	for _, name := range names {
+		if profile := fileProfile(name); profile != "" {
+			// files of other profiles are skipped, siblings of a base file are read right after it
+			if profile != cl.Profile || slices.Contains(names, profileBaseName(name)) {
+				continue
+			}
+		}
+		filePath := files.join(dir, name)
+		fileEntries, err := cl.readPath(ctx, files, filePath, includeChain)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+		if cl.Profile == "" || fileProfile(name) != "" {
+			continue
+		}
+		profileEntries, err := cl.readProfileSibling(ctx, files, filePath)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, profileEntries...)
+	}
+	return entries, nil
+}
+
+func (cl *ConfigLoader) readInclude(ctx context.Context, files fileSource, from string, target string, optional bool, includeChain []string) ([]fileEntry, error) {
+	if !files.isAbs(target) {
+		target = files.join(files.dir(from), target)
+	}
+	if _, err := files.stat(target); optional && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return cl.readPath(ctx, files, target, includeChain)
+}
+
+// readLines reads a key value file line by line, the errors of all lines are returned as ErrFileLine
+func (cl *ConfigLoader) readLines(ctx context.Context, files fileSource, file io.Reader, filePath string, includeChain []string) ([]fileEntry, error) {
+	entries := []fileEntry{}
+	errs := []error{}
 	scanner := bufio.NewScanner(file)
+	lineNumber := 0
 	for scanner.Scan() {
 		if err := ctx.Err(); err != nil {
-			return err
+			return nil, err
 		}
+		lineNumber++
 		line := scanner.Text()
-		errGroup.Go(func() error {
-			key, value, err := parseFileLine(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if target, optional, ok := parseIncludeDirective(line); ok {
+			included, err := cl.readInclude(ctx, files, filePath, target, optional, includeChain)
 			if err != nil {
-				return err
+				errs = append(errs, &ErrFileLine{path: filePath, line: lineNumber, raw: line, nested: err})
+				continue
 			}
-			return store.Set(ctx, key, value, false)
-		})
+			entries = append(entries, included...)
+			continue
+		}
+		key, value, err := parseFileLine(files, line)
+		if err != nil {
+			errs = append(errs, &ErrFileLine{path: filePath, line: lineNumber, raw: line, nested: err})
+			continue
+		}
+		if key == "" {
+			// comment
+			continue
+		}
+		entries = append(entries, fileEntry{key: key, value: value, path: filePath, line: lineNumber})
 	}
 	if err := scanner.Err(); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
 
+func (cl *ConfigLoader) readDotenv(ctx context.Context, files fileSource, file io.Reader, filePath string, includeChain []string) ([]fileEntry, error) {
+	dotenvEntries, err := parseDotenv(file, filePath)
+	if err != nil {
+		return nil, err
+	}
+	entries := []fileEntry{}
+	errs := []error{}
+	for _, e := range dotenvEntries {
+		if e.include {
+			included, err := cl.readInclude(ctx, files, filePath, e.value, e.optional, includeChain)
+			if err != nil {
+				errs = append(errs, &ErrFileLine{path: filePath, line: e.line, raw: e.raw, nested: err})
+				continue
+			}
+			entries = append(entries, included...)
+			continue
+		}
+		key, value, err := resolveEntry(files, e.key, e.value)
+		if err != nil {
+			errs = append(errs, &ErrFileLine{path: filePath, line: e.line, raw: e.raw, nested: err})
+			continue
+		}
+		entries = append(entries, fileEntry{key: key, value: value, path: filePath, line: e.line})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+// parseIncludeDirective checks if a line is an include directive and returns its target
+func parseIncludeDirective(line string) (string, bool, bool) {
+	line = strings.TrimSpace(line)
+	for _, directive := range []string{INCLUDE_DIRECTIVE, INCLUDE_OPTIONAL_DIRECTIVE} {
+		rest, ok := strings.CutPrefix(line, directive)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		target := strings.Trim(stripInlineComment(strings.TrimSpace(rest)), "\"'")
+		return target, directive == INCLUDE_OPTIONAL_DIRECTIVE, target != ""
+	}
+	return "", false, false
+}
+
+func readJSON(file io.Reader, filePath string) ([]fileEntry, error) {
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return entriesFromValues(values, filePath)
 }
 
-func parseFileLine(line string) (string, string, error) {
+// entriesFromValues flattens the values of a structured file into entries sorted by key
+func entriesFromValues(values map[string]interface{}, filePath string) ([]fileEntry, error) {
+	flatValues := make(map[string]string)
+	if err := flattenValues("", values, flatValues); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(flatValues))
+	for key := range flatValues {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	entries := make([]fileEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, fileEntry{key: key, value: flatValues[key], path: filePath})
+	}
+	return entries, nil
+}
+
+// flattenValues flattens nested maps and lists into tree keys, list items are indexed by position
+func flattenValues(baseKey string, value interface{}, flatValues map[string]string) error {
+	return walkValues(baseKey, value, func(key string, value any) error {
+		if value == nil {
+			// null values in files are treated as unset
+			return nil
+		}
+		formatted, err := formatValue(key, value)
+		if err != nil {
+			return err
+		}
+		flatValues[key] = formatted
+		return nil
+	})
+}
+
+// walkValues calls fn for every leaf of nested maps and lists, null leaves are passed as nil
+func walkValues(baseKey string, value interface{}, fn func(key string, value any) error) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			k := strings.TrimPrefix(baseKey+CONFIG_TREE_SEPARATOR+key, CONFIG_TREE_SEPARATOR)
+			if err := walkValues(k, val, fn); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			k := strings.TrimPrefix(baseKey+CONFIG_TREE_SEPARATOR+strconv.Itoa(i), CONFIG_TREE_SEPARATOR)
+			if err := walkValues(k, val, fn); err != nil {
+				return err
+			}
+		}
+	default:
+		return fn(baseKey, v)
+	}
+	return nil
+}
+
+func parseFileLine(files fileSource, line string) (string, string, error) {
 	if strings.HasPrefix(line, "#") {
 		return "", "", nil
 	}
@@ -117,10 +491,10 @@ func parseFileLine(line string) (string, string, error) {
 			key: line,
 		}
 	}
-	return handleEntry(line, split)
+	return handleEntry(files, line, split)
 }
 
-func handleEntry(rawString string, split string) (string, string, error) {
+func handleEntry(files fileSource, rawString string, split string) (string, string, error) {
 	parts := strings.SplitN(rawString, split, 2)
 	if len(parts) != 2 {
 		return "", "", &ErrKeyValueInvalid{
@@ -128,13 +502,16 @@ func handleEntry(rawString string, split string) (string, string, error) {
 			key:   parts[0],
 		}
 	}
+	return resolveEntry(files, parts[0], parts[1])
+}
 
-	key := strings.ReplaceAll(parts[0], ENV_SPLIT_CHAR, CONFIG_TREE_SEPARATOR)
-	value := parts[1]
+// resolveEntry converts a raw key to a config key and resolves file references through files
+func resolveEntry(files fileSource, rawKey string, value string) (string, string, error) {
+	key := strings.ReplaceAll(rawKey, ENV_SPLIT_CHAR, CONFIG_TREE_SEPARATOR)
 	var err error
 	if strings.HasSuffix(key, CONFIG_TREE_SEPARATOR+"FILE") {
 		key = strings.TrimSuffix(key, CONFIG_TREE_SEPARATOR+"FILE")
-		v, err := handleFileEntry(value)
+		v, err := handleFileEntry(files, value)
 		if err != nil {
 			return "", "", err
 		}
@@ -144,24 +521,14 @@ func handleEntry(rawString string, split string) (string, string, error) {
 	return key, value, err
 }
 
-func handleFileEntry(path string) ([]byte, error) {
-	var err error
-	file, err := os.Open(path)
+// handleFileEntry reads the value of a KEY_FILE entry from files.
+// Contents are kept byte-exact apart from a single trailing line break.
+func handleFileEntry(files fileSource, path string) ([]byte, error) {
+	content, err := readSecretFile(files, path, DEFAULT_SECRET_MAX_SIZE)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-
-	var buffer bytes.Buffer
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		buffer.Write(scanner.Bytes())
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	bytes := buffer.Bytes()
-	return bytes, nil
+	return trimNewline(content), nil
 }
 
 // func nestedMapToString(m map[string]interface{}) string {
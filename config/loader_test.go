@@ -2,7 +2,13 @@ package config
 
 import (
 	"context"
+	"errors"
+	"io/fs"
+	"maps"
 	"os"
+	"path"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -73,3 +79,266 @@ func TestLoadEnvFromFile(t *testing.T) {
 		t.Fatal("Config is not loaded correctly (level 0)")
 	}
 }
+
+func TestLoadDotenvFile(t *testing.T) {
+	content := `# dotenv style config
+export SIMPLE=value # comment
+QUOTED="with # hash"
+NESTED_KEY='literal\n'
+MULTI="line1
+line2"
+`
+	filePath := path.Join(t.TempDir(), "test.env")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.TODO()
+	store := &ConfigStoreImpl{
+		mu:    sync.RWMutex{},
+		store: make(map[string]string),
+	}
+	loader := &ConfigLoader{Dotenv: true}
+	if err := loader.LoadFile(ctx, store, []string{filePath}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"SIMPLE":     "value",
+		"QUOTED":     "with # hash",
+		"NESTED/KEY": `literal\n`,
+		"MULTI":      "line1\nline2",
+	}
+	if !maps.Equal(store.store, expected) {
+		t.Errorf("Unexpected store: %q", store.store)
+	}
+}
+
+func TestLoadConfDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.conf":              "NAME=main\nPORT=80\nLEVEL=INFO\n",
+		"conf.d/10-port.conf":    "PORT=8080\n",
+		"conf.d/20-level.env":    "LEVEL=DEBUG\n",
+		"conf.d/30-extra.json":   `{"extra": {"list": ["a", "b"], "number": 1.5, "flag": true}, "port": 9090}`,
+		"conf.d/40-ignored.txt":  "NAME=ignored\n",
+		"conf.d/.hidden.conf":    "NAME=hidden\n",
+		"conf.d/nested/sub.conf": "NAME=nested\n",
+	})
+	store := &ConfigStoreImpl{
+		mu:    sync.RWMutex{},
+		store: make(map[string]string),
+	}
+	loader := &ConfigLoader{}
+	if err := loader.LoadFile(context.TODO(), store, []string{path.Join(dir, "main.conf"), path.Join(dir, "conf.d")}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"NAME":         "main",
+		"PORT":         "9090",
+		"LEVEL":        "DEBUG",
+		"EXTRA/LIST/0": "a",
+		"EXTRA/LIST/1": "b",
+		"EXTRA/NUMBER": "1.5",
+		"EXTRA/FLAG":   "true",
+	}
+	if !maps.Equal(store.store, expected) {
+		t.Errorf("Unexpected store: %q", store.store)
+	}
+}
+
+func TestLoadFileInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.conf":         "A=main\ninclude base/base.conf\nB=main\ninclude? missing.conf\n",
+		"base/base.conf":    "A=base\nB=base\nC=base\ninclude conf.d\n",
+		"base/conf.d/x.env": "D=included dir\n",
+	})
+	for _, dotenv := range []bool{false, true} {
+		store := &ConfigStoreImpl{
+			mu:    sync.RWMutex{},
+			store: make(map[string]string),
+		}
+		loader := &ConfigLoader{Dotenv: dotenv}
+		if err := loader.LoadFile(context.TODO(), store, []string{path.Join(dir, "main.conf")}); err != nil {
+			t.Fatal(err)
+		}
+		// the include is applied at its position, later lines override included values
+		expected := map[string]string{
+			"A": "base",
+			"B": "main",
+			"C": "base",
+			"D": "included dir",
+		}
+		if !maps.Equal(store.store, expected) {
+			t.Errorf("Unexpected store (dotenv %t): %q", dotenv, store.store)
+		}
+	}
+}
+
+func TestLoadFileIncludeMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"main.conf": "A=main\ninclude missing.conf\n",
+	})
+	store := &ConfigStoreImpl{
+		mu:    sync.RWMutex{},
+		store: make(map[string]string),
+	}
+	loader := &ConfigLoader{}
+	if err := loader.LoadFile(context.TODO(), store, []string{path.Join(dir, "main.conf")}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected missing file error, got %v", err)
+	}
+}
+
+func TestLoadFileIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.conf":        "A=1\ninclude b.conf\n",
+		"b.conf":        "B=1\ninclude sub/c.conf\n",
+		"sub/c.conf":    "C=1\ninclude ../a.conf\n",
+		"self.d/x.conf": "include ..\n",
+	})
+	loader := &ConfigLoader{}
+	for _, file := range []string{"a.conf", "self.d"} {
+		store := &ConfigStoreImpl{
+			mu:    sync.RWMutex{},
+			store: make(map[string]string),
+		}
+		err := loader.LoadFile(context.TODO(), store, []string{path.Join(dir, file)})
+		var cycleErr *ErrIncludeCycle
+		if !errors.As(err, &cycleErr) {
+			t.Errorf("Expected include cycle error for %s, got %v", file, err)
+		}
+	}
+}
+
+func TestLoadEnviron(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	environ := []string{"ENVIRONTEST_DB_HOST=localhost", "ENVIRONTEST_PORT=8080", "OTHER_PORT=1"}
+	loader := &ConfigLoader{}
+	if err := loader.LoadEnviron(ctx, store, environ, []string{"ENVIRONTEST"}); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(store.(*ConfigStoreImpl).store, map[string]string{"DB/HOST": "localhost", "PORT": "8080"}) {
+		t.Errorf("Unexpected store %v", store.(*ConfigStoreImpl).store)
+	}
+	if _, ok := os.LookupEnv("ENVIRONTEST_PORT"); ok {
+		t.Error("LoadEnviron must not modify the process environment")
+	}
+}
+
+func TestLoadFileDuplicateKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.conf": "A=1\nB=1\na=2\nA=3\nB: 2\n",
+	})
+	// the last line of a key wins, independent of scheduling
+	for i := 0; i < 20; i++ {
+		store := &ConfigStoreImpl{
+			mu:    sync.RWMutex{},
+			store: make(map[string]string),
+		}
+		if err := (&ConfigLoader{}).LoadFile(context.TODO(), store, []string{path.Join(dir, "app.conf")}); err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(store.store, map[string]string{"A": "3", "B": "2"}) {
+			t.Fatalf("Unexpected store %q", store.store)
+		}
+	}
+}
+
+func TestLoadEnvironPrefixOrder(t *testing.T) {
+	ctx := context.TODO()
+	environ := []string{"SVC_PORT=2", "SVC_NAME=svc", "APP_PORT=1"}
+	// the first prefix of the list wins, independent of scheduling
+	for i := 0; i < 20; i++ {
+		store := &ConfigStoreImpl{
+			mu:    sync.RWMutex{},
+			store: make(map[string]string),
+		}
+		if err := (&ConfigLoader{}).LoadEnviron(ctx, store, environ, []string{"APP", "SVC"}); err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(store.store, map[string]string{"PORT": "1", "NAME": "svc"}) {
+			t.Fatalf("Unexpected store %q", store.store)
+		}
+	}
+}
+
+func TestLoadFileDuplicateAlias(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.conf": "NEWNAME=new\nOLDNAME=old\n",
+	})
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("NEWNAME", "OLDNAME"); err != nil {
+		t.Fatal(err)
+	}
+	// an alias counts as its key, so the last line wins
+	if err := config.loader.LoadFile(ctx, config, []string{path.Join(dir, "app.conf")}); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "NEWNAME"); err != nil || value != "old" {
+		t.Errorf("Expected 'old', got '%s' (%v)", value, err)
+	}
+}
+
+func TestLoadFileLineErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.conf": "A=1\ninvalid line\nB=2\nC_FILE=missing.txt\nalso invalid\n",
+		"app.env":  "A=1\nMY KEY=x\nB=\"unterminated\n",
+	})
+	tests := []struct {
+		file   string
+		dotenv bool
+		lines  []int
+		raw    string
+	}{
+		{"app.conf", false, []int{2, 4, 5}, "invalid line"},
+		{"app.env", true, []int{2, 3}, "MY KEY=x"},
+	}
+	for _, test := range tests {
+		store := &ConfigStoreImpl{
+			mu:    sync.RWMutex{},
+			store: make(map[string]string),
+		}
+		loader := &ConfigLoader{Dotenv: test.dotenv}
+		filePath := path.Join(dir, test.file)
+		err := loader.LoadFile(context.TODO(), store, []string{filePath})
+		if err == nil {
+			t.Fatalf("Expected error for %s", test.file)
+		}
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Fatalf("Expected all errors of %s, got %v", test.file, err)
+		}
+		lines := []int{}
+		for _, lineErr := range joined.Unwrap() {
+			var fileLineErr *ErrFileLine
+			if !errors.As(lineErr, &fileLineErr) || fileLineErr.path != filePath {
+				t.Fatalf("Expected file line error for %s, got %v", test.file, lineErr)
+			}
+			if !errors.Is(lineErr, ErrLoadingConfig) {
+				t.Errorf("Expected %v to wrap ErrLoadingConfig", lineErr)
+			}
+			lines = append(lines, fileLineErr.line)
+		}
+		if !slices.Equal(lines, test.lines) {
+			t.Errorf("Expected errors on lines %v of %s, got %v", test.lines, test.file, lines)
+		}
+		if !strings.Contains(err.Error(), test.raw) {
+			t.Errorf("Expected error to contain the raw line '%s', got %v", test.raw, err)
+		}
+		if len(store.store) != 0 {
+			t.Errorf("Expected nothing to be loaded from %s, got %q", test.file, store.store)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+const MATCH_ANY_DEPTH = "**"
+
+// Match returns all values whose key matches pattern, indexed by their full key.
+// Patterns are matched segment-wise, each segment supports the syntax of path.Match
+// ('*', '?' and character classes) and '**' matches any number of segments, including none.
+// Matching is case-insensitive, like key lookups.
+func (c *Config) Match(ctx context.Context, pattern string) (map[string]string, error) {
+	patternSegments, err := parseKeyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// only the subtree below the literal part of the pattern needs to be searched
+	literal := []string{}
+	for _, segment := range patternSegments {
+		if hasWildcard(segment) {
+			break
+		}
+		literal = append(literal, segment)
+	}
+	values, err := c.values(ctx, strings.Join(literal, CONFIG_TREE_SEPARATOR))
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string]string)
+	for key, value := range values {
+		if matchSegments(patternSegments, strings.Split(key, CONFIG_TREE_SEPARATOR)) {
+			matches[key] = value
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// MatchKey reports whether key matches pattern, see Config.Match for the pattern syntax
+func MatchKey(pattern string, key string) (bool, error) {
+	patternSegments, err := parseKeyPattern(pattern)
+	if err != nil {
+		return false, err
+	}
+	key = normalizePrefix(key)
+	return matchSegments(patternSegments, strings.Split(key, CONFIG_TREE_SEPARATOR)), nil
+}
+
+func parseKeyPattern(pattern string) ([]string, error) {
+	normalized := normalizePrefix(pattern)
+	if normalized == "" {
+		return nil, &ErrPatternInvalid{pattern: pattern}
+	}
+	segments := strings.Split(normalized, CONFIG_TREE_SEPARATOR)
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, &ErrPatternInvalid{pattern: pattern}
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, &ErrPatternInvalid{pattern: pattern, nested: err}
+		}
+	}
+	return segments, nil
+}
+
+func hasWildcard(segment string) bool {
+	return strings.ContainsAny(segment, "*?[\\")
+}
+
+func matchSegments(pattern []string, key []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == MATCH_ANY_DEPTH {
+			// try to match the rest of the pattern at every remaining depth
+			for i := 0; i <= len(key); i++ {
+				if matchSegments(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(key) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], key[0]); !ok {
+			return false
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	config, err := WithInitialValues(context.TODO(), map[string]interface{}{
+		"name": "match",
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"port": 80,
+				"tls": map[string]interface{}{
+					"port": 443,
+				},
+			},
+			"db": map[string]interface{}{
+				"port": 5432,
+				"host": "db.local",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.TODO()
+	cases := map[string]map[string]string{
+		"SERVICES/*/PORT": {
+			"SERVICES/WEB/PORT": "80",
+			"SERVICES/DB/PORT":  "5432",
+		},
+		"services/*/port": {
+			"SERVICES/WEB/PORT": "80",
+			"SERVICES/DB/PORT":  "5432",
+		},
+		"SERVICES/**/PORT": {
+			"SERVICES/WEB/PORT":     "80",
+			"SERVICES/WEB/TLS/PORT": "443",
+			"SERVICES/DB/PORT":      "5432",
+		},
+		"**/PORT": {
+			"SERVICES/WEB/PORT":     "80",
+			"SERVICES/WEB/TLS/PORT": "443",
+			"SERVICES/DB/PORT":      "5432",
+		},
+		"SERVICES/D?/*": {
+			"SERVICES/DB/PORT": "5432",
+			"SERVICES/DB/HOST": "db.local",
+		},
+		"SERVICES/[W]*/**": {
+			"SERVICES/WEB/PORT":     "80",
+			"SERVICES/WEB/TLS/PORT": "443",
+		},
+		"NAME":             {"NAME": "match"},
+		"NAME/**":          {"NAME": "match"},
+		"*":                {"NAME": "match"},
+		"SERVICES/*":       {},
+		"MISSING/**":       {},
+		"SERVICES/*/PORTS": {},
+	}
+	for pattern, expected := range cases {
+		matches, err := config.Match(ctx, pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", pattern, err)
+			continue
+		}
+		if !maps.Equal(matches, expected) {
+			t.Errorf("%s: expected %v, got %v", pattern, expected, matches)
+		}
+	}
+}
+
+func TestMatchInvalidPattern(t *testing.T) {
+	config, err := New(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pattern := range []string{"", "A//B", "SERVICES/[/PORT"} {
+		_, err := config.Match(context.TODO(), pattern)
+		var patternErr *ErrPatternInvalid
+		if !errors.As(err, &patternErr) {
+			t.Errorf("%s: expected pattern error, got %v", pattern, err)
+		}
+	}
+}
+
+func TestMatchKey(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"A/*/C", "A/B/C", true},
+		{"A/*/C", "A/B/B/C", false},
+		{"A/**/C", "A/C", true},
+		{"A/**/C", "A/B/B/C", true},
+		{"A/**", "A", true},
+		{"**", "A/B", true},
+		{"a/b", "A/B", true},
+		{"A/B*", "A/BC", true},
+		{"A/B*", "A/B/C", false},
+	}
+	for _, tc := range cases {
+		if match, err := MatchKey(tc.pattern, tc.key); err != nil || match != tc.match {
+			t.Errorf("MatchKey(%s, %s): expected %t, got %t (%v)", tc.pattern, tc.key, tc.match, match, err)
+		}
+	}
+}
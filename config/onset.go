@@ -0,0 +1,287 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SetHook validates or normalizes a value before it is stored and returns the value to store.
+// key is the canonical upper case key, returning an error rejects the value.
+// r reads the config the value is written to. In a transaction the hooks run once the transaction
+// function returned, r sees all values written by the transaction as they were passed to it.
+type SetHook func(ctx context.Context, r Reader, key string, value string) (string, error)
+
+// Reader reads the values of a config or transaction
+type Reader interface {
+	Get(ctx context.Context, key string) (string, error)
+	Has(ctx context.Context, key string) bool
+}
+
+// setHooks holds the hooks registered with OnSet in registration order
+type setHooks struct {
+	mu    sync.RWMutex
+	hooks []patternHook
+}
+
+type patternHook struct {
+	segments []string
+	// prefix holds the segments above the keys of a subtree config, see GetConfig
+	prefix []string
+	hook   SetHook
+}
+
+// OnSet registers hooks for the keys matching pattern, see Match for the pattern syntax.
+// Hooks run in order on every write through the config, including loading, merging and transactions,
+// each hook gets the value returned by the previous one. Register hooks before sharing the config.
+func (c *Config) OnSet(pattern string, hooks ...SetHook) error {
+	segments, err := parseKeyPattern(pattern)
+	if err != nil {
+		return err
+	}
+	if c.setHooks == nil {
+		c.setHooks = &setHooks{}
+	}
+	c.setHooks.mu.Lock()
+	defer c.setHooks.mu.Unlock()
+	for _, hook := range hooks {
+		c.setHooks.hooks = append(c.setHooks.hooks, patternHook{segments: segments, hook: hook})
+	}
+	return nil
+}
+
+// applySetHooks runs the hooks matching key, rejected values are returned as ErrKeyValueInvalid
+func (c *Config) applySetHooks(ctx context.Context, r Reader, key string, value string) (string, error) {
+	if c.setHooks == nil {
+		return value, nil
+	}
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if IsValidKey(key) != nil {
+		// the store reports the invalid key
+		return value, nil
+	}
+	c.setHooks.mu.RLock()
+	hooks := slices.Clone(c.setHooks.hooks)
+	c.setHooks.mu.RUnlock()
+
+	keySegments := strings.Split(key, CONFIG_TREE_SEPARATOR)
+	result := value
+	for _, h := range hooks {
+		if !matchSegments(h.segments, append(slices.Clip(h.prefix), keySegments...)) {
+			continue
+		}
+		var err error
+		if result, err = h.hook(ctx, r, key, result); err != nil {
+			return "", &ErrKeyValueInvalid{key: key, value: value, nested: err}
+		}
+	}
+	return result, nil
+}
+
+// applyTypedSetHooks runs the hooks on the string form of value,
+// the typed value is kept unless a hook changed the string
+func (c *Config) applyTypedSetHooks(ctx context.Context, r Reader, key string, value any) (any, error) {
+	if c.setHooks == nil {
+		return value, nil
+	}
+	formatted, err := formatValue(key, value)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.applySetHooks(ctx, r, key, formatted)
+	if err != nil {
+		return nil, err
+	}
+	if result != formatted {
+		return result, nil
+	}
+	return value, nil
+}
+
+// clone copies the registered hooks
+func (h *setHooks) clone() *setHooks {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return &setHooks{hooks: slices.Clone(h.hooks)}
+}
+
+// empty checks if no hooks are registered
+func (h *setHooks) empty() bool {
+	if h == nil {
+		return true
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.hooks) == 0
+}
+
+// below copies the hooks for the config of the subtree at prefix, they keep matching the full keys
+func (h *setHooks) below(prefix string) *setHooks {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	below := &setHooks{hooks: make([]patternHook, 0, len(h.hooks))}
+	for _, hook := range h.hooks {
+		hook.prefix = append(slices.Clip(hook.prefix), strings.Split(prefix, CONFIG_TREE_SEPARATOR)...)
+		below.hooks = append(below.hooks, hook)
+	}
+	return below
+}
+
+// hookTx runs the set hooks of a config for writes in a transaction.
+// Writes are staged as given and the hooks run once the transaction function returned,
+// so hooks can read all values written by the transaction regardless of their order.
+type hookTx struct {
+	tx      Tx
+	config  *Config
+	pending []pendingSet
+}
+
+// pendingSet is a write whose hooks did not run yet
+type pendingSet struct {
+	ctx   context.Context
+	key   string
+	value any
+}
+
+func (t *hookTx) Get(ctx context.Context, key string) (string, error) {
+	return t.tx.Get(ctx, key)
+}
+
+func (t *hookTx) Has(ctx context.Context, key string) bool {
+	return t.tx.Has(ctx, key)
+}
+
+func (t *hookTx) Set(ctx context.Context, key string, value string) error {
+	if err := t.tx.Set(ctx, key, value); err != nil {
+		return err
+	}
+	t.track(ctx, key, value)
+	return nil
+}
+
+func (t *hookTx) SetTyped(ctx context.Context, key string, value any) error {
+	if err := setTx(ctx, t.tx, key, value); err != nil {
+		return err
+	}
+	t.track(ctx, key, value)
+	return nil
+}
+
+func (t *hookTx) Delete(ctx context.Context, key string) error {
+	if err := t.tx.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.track(ctx, key, nil)
+	return nil
+}
+
+// track records the last write of key, deletes are recorded as nil so no hooks run
+func (t *hookTx) track(ctx context.Context, key string, value any) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	t.pending = slices.DeleteFunc(t.pending, func(p pendingSet) bool { return p.key == key })
+	if value != nil {
+		t.pending = append(t.pending, pendingSet{ctx: ctx, key: key, value: value})
+	}
+}
+
+// apply runs the hooks of the staged writes and stages the values they return
+func (t *hookTx) apply() error {
+	var r Reader = t.tx
+	if t.config.aliases != nil {
+		// hooks read with aliases like the caller
+		r = &aliasTx{tx: t.tx, config: t.config}
+	}
+	for _, p := range t.pending {
+		formatted, err := formatValue(p.key, p.value)
+		if err != nil {
+			return err
+		}
+		hooked, err := t.config.applySetHooks(p.ctx, r, p.key, formatted)
+		if err != nil {
+			return err
+		}
+		// typed values are kept unless a hook changed the string form
+		if hooked != formatted {
+			if err := t.tx.Set(p.ctx, p.key, hooked); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type sourceFileKey struct{}
+
+// sourceFile is the file a value is loaded from and the file system it is read from
+type sourceFile struct {
+	path  string
+	files fileSource
+}
+
+// SourceFile returns the file a value is being loaded from, for use in set hooks.
+// It is empty for values that are not read from a file, e.g. environment variables.
+// Files loaded with LoadFS are slash separated paths relative to the root of the fs.FS.
+func SourceFile(ctx context.Context) string {
+	source, _ := ctx.Value(sourceFileKey{}).(sourceFile)
+	return source.path
+}
+
+func withSourceFile(ctx context.Context, files fileSource, path string) context.Context {
+	if path == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sourceFileKey{}, sourceFile{path: path, files: files})
+}
+
+// TrimValue removes leading and trailing white space
+func TrimValue(ctx context.Context, r Reader, key string, value string) (string, error) {
+	return strings.TrimSpace(value), nil
+}
+
+// LowerValue converts the value to lower case
+func LowerValue(ctx context.Context, r Reader, key string, value string) (string, error) {
+	return strings.ToLower(value), nil
+}
+
+// ExpandHome replaces a leading ~ with the home directory of the current user
+func ExpandHome(ctx context.Context, r Reader, key string, value string) (string, error) {
+	if value != "~" && !strings.HasPrefix(value, "~/") {
+		return value, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, value[1:]), nil
+}
+
+// ResolvePath resolves relative paths against the directory of the file the value is loaded from,
+// values not loaded from a file are kept. For files loaded with LoadFS the result is relative to the root of the fs.FS.
+func ResolvePath(ctx context.Context, r Reader, key string, value string) (string, error) {
+	source, _ := ctx.Value(sourceFileKey{}).(sourceFile)
+	if value == "" || source.path == "" || source.files.isAbs(value) {
+		return value, nil
+	}
+	return source.files.join(source.files.dir(source.path), value), nil
+}
+
+// OneOf rejects values that are not one of allowed, compared case-insensitively
+func OneOf(allowed ...string) SetHook {
+	return func(ctx context.Context, r Reader, key string, value string) (string, error) {
+		for _, a := range allowed {
+			if strings.EqualFold(a, value) {
+				return value, nil
+			}
+		}
+		return "", &ErrValueNotAllowed{value: value, allowed: allowed}
+	}
+}
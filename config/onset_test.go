@@ -0,0 +1,210 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestOnSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("MODE", TrimValue, LowerValue, OneOf("dev", "prod")); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("*/NAME", TrimValue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.Set(ctx, "mode", "  PROD ", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "DB/NAME", " app ", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "DB/USER", " admin ", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"MODE": "prod", "DB/NAME": "app", "DB/USER": " admin "}, true); err != nil {
+		t.Error(err)
+	}
+
+	err = config.Set(ctx, "MODE", "test", true)
+	var notAllowed *ErrValueNotAllowed
+	if !errors.As(err, &notAllowed) || !errors.Is(err, ErrValueInvalid) {
+		t.Errorf("Expected rejected value, got %v", err)
+	}
+	if value, _ := config.Get(ctx, "MODE"); value != "prod" {
+		t.Errorf("Expected rejected value not to be stored, got '%s'", value)
+	}
+
+	if err := config.OnSet("[", TrimValue); err == nil {
+		t.Error("Expected invalid pattern to be rejected")
+	}
+}
+
+func TestOnSetWrites(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("PORT", "LISTEN"); err != nil {
+		t.Fatal(err)
+	}
+	hookedKeys := []string{}
+	if err := config.OnSet("**", func(ctx context.Context, r Reader, key string, value string) (string, error) {
+		hookedKeys = append(hookedKeys, key)
+		return value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("PORT", TrimValue); err != nil {
+		t.Fatal(err)
+	}
+
+	// typed values keep their type if the hooks do not change them
+	if err := Set(ctx, config, "listen", 8080, false); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := config.GetTyped(ctx, "PORT"); err != nil || raw != 8080 {
+		t.Errorf("Expected typed value, got %v (%v)", raw, err)
+	}
+
+	source, err := WithInitialValues(ctx, map[string]interface{}{"port": " 9090 ", "name": "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Merge(ctx, source, true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := config.Get(ctx, "PORT"); value != "9090" {
+		t.Errorf("Expected merged value to be normalized, got '%s'", value)
+	}
+	if len(hookedKeys) != 3 || hookedKeys[0] != "PORT" {
+		t.Errorf("Unexpected hooked keys %v", hookedKeys)
+	}
+
+	// hooks are kept by copies
+	copied, err := config.Copy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := copied.Set(ctx, "PORT", " 1 ", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := copied.Get(ctx, "PORT"); value != "1" {
+		t.Errorf("Expected copy to run hooks, got '%s'", value)
+	}
+}
+
+func TestOnSetLoad(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"conf/app.env": "DATA_DIR=data\nLOG_PATH=/var/log/app.log\nCACHE_DIR=~/cache\nLEVEL=loud\n",
+	})
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("*/DIR", ExpandHome, ResolvePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.OnSet("LOG/PATH", ResolvePath); err != nil {
+		t.Fatal(err)
+	}
+	config.loader = &ConfigLoader{Env: EnvMap(map[string]string{"HOOKTEST_WORK_DIR": "work"})}
+	if err := config.Load(ctx, []string{"HOOKTEST"}, []string{path.Join(dir, "conf/app.env")}); err != nil {
+		t.Fatal(err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"DATA/DIR":  filepath.Join(dir, "conf", "data"),
+		"LOG/PATH":  "/var/log/app.log",
+		"CACHE/DIR": filepath.Join(home, "cache"),
+		// environment values are not resolved against a file
+		"WORK/DIR": "work",
+		"LEVEL":    "loud",
+	}
+	if err := config.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+
+	// rejected values fail the load
+	if err := config.OnSet("LEVEL", OneOf("debug", "info")); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.DeletePrefix(ctx, "LEVEL"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Load(ctx, nil, []string{path.Join(dir, "conf/app.env")}); !errors.Is(err, ErrValueInvalid) {
+		t.Errorf("Expected invalid value error, got %v", err)
+	}
+}
+
+func TestOnSetReadsOtherKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Alias("MIN", "LOWER"); err != nil {
+		t.Fatal(err)
+	}
+	errRange := errors.New("max below min")
+	if err := config.OnSet("MAX", func(ctx context.Context, r Reader, key string, value string) (string, error) {
+		if !r.Has(ctx, "lower") {
+			return value, nil
+		}
+		min, err := r.Get(ctx, "lower")
+		if err != nil {
+			return "", err
+		}
+		if len(value) < len(min) || len(value) == len(min) && value < min {
+			return "", errRange
+		}
+		return value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.Set(ctx, "MIN", "10", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "MAX", "5", false); !errors.Is(err, errRange) {
+		t.Errorf("Expected range error, got %v", err)
+	}
+
+	// hooks see the values staged by the same merge
+	source, err := WithInitialValues(ctx, map[string]interface{}{"min": "1", "max": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Merge(ctx, source, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"MIN": "1", "MAX": "5"}, true); err != nil {
+		t.Error(err)
+	}
+	source, err = WithInitialValues(ctx, map[string]interface{}{"min": "50", "max": "20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Merge(ctx, source, true); !errors.Is(err, errRange) {
+		t.Errorf("Expected range error, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+type Option func(*options)
+
+type options struct {
+	profile    string
+	profileKey string
+	profileEnv string
+	envSource  EnvSource
+	newStore   ConfigStoreNew
+	loader     Loader
+	deprecated DeprecationHandler
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithProfile selects the profile applied while loading
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithProfileKey reads the profile from a key of the loaded config, if no profile was set otherwise
+func WithProfileKey(key string) Option {
+	return func(o *options) {
+		o.profileKey = key
+	}
+}
+
+// WithProfileEnv reads the profile from an environment variable (e.g. APP_ENV), if no profile was set otherwise
+func WithProfileEnv(name string) Option {
+	return func(o *options) {
+		o.profileEnv = name
+	}
+}
+
+// WithEnvSource reads environment variables from source instead of the process environment,
+// this includes the variable of WithProfileEnv
+func WithEnvSource(source EnvSource) Option {
+	return func(o *options) {
+		o.envSource = source
+	}
+}
+
+// WithStore creates the stores of the config and of configs derived from it with newStore,
+// instead of DefaultConfigStore
+func WithStore(newStore ConfigStoreNew) Option {
+	return func(o *options) {
+		o.newStore = newStore
+	}
+}
+
+// WithLoader loads the config with loader instead of a ConfigLoader.
+// A ConfigLoader passed here gets the profile and env source of the other options, unless it sets its own.
+func WithLoader(loader Loader) Option {
+	return func(o *options) {
+		o.loader = loader
+	}
+}
+
+// loaderFor returns the loader to use for profile.
+// Profiles can only be applied by a ConfigLoader, other loaders return ErrProfileUnsupported.
+func (o *options) loaderFor(profile string) (Loader, error) {
+	if o.loader == nil {
+		return &ConfigLoader{Profile: profile, Env: o.envSource}, nil
+	}
+	if configLoader, ok := o.loader.(*ConfigLoader); ok {
+		loader := *configLoader
+		if loader.Profile == "" {
+			loader.Profile = profile
+		}
+		if loader.Env == nil {
+			loader.Env = o.envSource
+		}
+		return &loader, nil
+	}
+	if profile != "" {
+		return nil, ErrProfileUnsupported
+	}
+	return o.loader, nil
+}
+
+// WithDeprecationHandler calls handler when a deprecated key of the config is used,
+// instead of logging a warning. Configs derived from the config keep the handler.
+func WithDeprecationHandler(handler DeprecationHandler) Option {
+	return func(o *options) {
+		o.deprecated = handler
+	}
+}
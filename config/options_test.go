@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+)
+
+// countingFactory creates in memory stores and counts them
+type countingFactory struct {
+	created int
+}
+
+func (f *countingFactory) newStore(ctx context.Context) (ConfigStore, error) {
+	f.created++
+	return NewConfigStore(ctx)
+}
+
+func TestWithStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	factory := &countingFactory{}
+	config, err := WithInitialValues(ctx, map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}}, WithStore(factory.newStore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory.created != 1 {
+		t.Fatalf("Expected the config to use the factory, got %d stores", factory.created)
+	}
+
+	// derived configs inherit the factory
+	sub, err := config.GetConfig(ctx, "DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := sub.Copy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Snapshot().Copy(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if factory.created != 4 {
+		t.Errorf("Expected derived configs to use the factory, got %d stores", factory.created)
+	}
+	if value, err := copied.Get(ctx, "HOST"); err != nil || value != "localhost" {
+		t.Errorf("Unexpected value '%s' (%v)", value, err)
+	}
+
+	errFactory := errors.New("no backend")
+	if _, err := New(ctx, WithStore(func(ctx context.Context) (ConfigStore, error) { return nil, errFactory })); !errors.Is(err, errFactory) {
+		t.Errorf("Expected factory error, got %v", err)
+	}
+}
+
+// stubLoader sets a fixed key instead of reading the environment and files
+type stubLoader struct {
+	files []string
+}
+
+func (l *stubLoader) LoadEnv(ctx context.Context, store ConfigStore, prefixList []string) error {
+	return store.Set(ctx, "SOURCE", "stub", false)
+}
+
+func (l *stubLoader) LoadFile(ctx context.Context, store ConfigStore, paths []string) error {
+	l.files = append(l.files, paths...)
+	return nil
+}
+
+func TestWithLoader(t *testing.T) {
+	t.Parallel()
+	ctx := context.TODO()
+	loader := &stubLoader{}
+	config, err := NewLoadedConfig(ctx, []string{"LOADERTEST"}, []string{"app.env"}, WithLoader(loader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "SOURCE"); err != nil || value != "stub" {
+		t.Errorf("Expected values of the loader, got '%s' (%v)", value, err)
+	}
+	if len(loader.files) != 1 || loader.files[0] != "app.env" {
+		t.Errorf("Unexpected files %v", loader.files)
+	}
+
+	// a ConfigLoader gets the profile and env source of the options
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.env":      "NAME=file\n",
+		"app.prod.env": "NAME=prod\n",
+	})
+	config, err = NewLoadedConfig(ctx, []string{"LOADERTEST"}, []string{path.Join(dir, "app.env")},
+		WithLoader(&ConfigLoader{Dotenv: true}), WithProfile("prod"), WithEnvSource(EnvMap(map[string]string{"LOADERTEST_LEVEL": "debug"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"NAME": "prod", "LEVEL": "debug"}, true); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+const PROFILES_KEY = "PROFILES"
+
+// resolveProfile determines the active profile, an explicit profile takes precedence
+// over the environment variable, which takes precedence over the config key.
+// The config key is read by loading config without a profile, loaded reports if config was loaded.
+func resolveProfile(ctx context.Context, config *Config, opts *options, envPrefixList []string, fileList []string) (profile string, loaded bool, err error) {
+	profile = opts.profile
+	if profile == "" && opts.profileEnv != "" {
+		value, err := lookupEnv(opts.envSource, opts.profileEnv)
+		if err != nil {
+			return "", false, err
+		}
+		profile = value
+	}
+	if profile == "" && opts.profileKey != "" {
+		if config.loader, err = opts.loaderFor(""); err != nil {
+			return "", false, err
+		}
+		if err := config.Load(ctx, envPrefixList, fileList); err != nil {
+			return "", false, err
+		}
+		loaded = true
+		value, err := config.Get(ctx, opts.profileKey)
+		var notFound *ErrKeyNotFound
+		if err != nil && !errors.As(err, &notFound) {
+			return "", false, err
+		}
+		profile = value
+	}
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return "", loaded, nil
+	}
+	if err := checkSegment(strings.ToUpper(profile)); err != nil {
+		return "", false, &ErrKeyValueInvalid{key: PROFILES_KEY, value: profile, nested: err}
+	}
+	if loaded {
+		// the profile changes the loaded files and overlays, so the config is loaded again
+		store, err := config.createStore(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		config.ConfigStore = store
+	}
+	return profile, false, nil
+}
+
+// profileFileName returns the sibling file of a profile, e.g. app.prod.env for app.env
+func profileFileName(filePath string, profile string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + profile + ext
+}
+
+// fileProfile returns the profile of a file like app.prod.env, or "" for a base file like app.env
+func fileProfile(name string) string {
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	if index := strings.LastIndex(stem, "."); index >= 0 {
+		return stem[index+1:]
+	}
+	return ""
+}
+
+// profileBaseName returns the base file of a profile file, e.g. app.env for app.prod.env
+func profileBaseName(name string) string {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	return strings.TrimSuffix(stem, "."+fileProfile(name)) + ext
+}
+
+// readProfileSibling reads the profile sibling of a file, a missing sibling is not an error.
+// Directories have no sibling, readDir picks the profile files inside them.
+func (cl *ConfigLoader) readProfileSibling(ctx context.Context, files fileSource, filePath string) ([]fileEntry, error) {
+	if info, err := files.stat(filePath); err == nil && info.IsDir() {
+		return nil, nil
+	}
+	siblingPath := profileFileName(filePath, cl.Profile)
+	if info, err := files.stat(siblingPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if info.IsDir() {
+		return nil, nil
+	}
+	return cl.readPath(ctx, files, siblingPath, nil)
+}
+
+// applyProfile appends the values of the PROFILES/<profile> subtree as top level entries,
+// so they override the base values
+func applyProfile(entries []fileEntry, profile string) []fileEntry {
+	profilePrefix := PROFILES_KEY + CONFIG_TREE_SEPARATOR + strings.ToUpper(profile) + CONFIG_TREE_SEPARATOR
+	overlay := []fileEntry{}
+	for _, entry := range entries {
+		key := strings.ToUpper(strings.TrimSpace(entry.key))
+		if !strings.HasPrefix(key, profilePrefix) || key == profilePrefix {
+			continue
+		}
+		entry.key = strings.TrimPrefix(key, profilePrefix)
+		overlay = append(overlay, entry)
+	}
+	return append(entries, overlay...)
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+)
+
+func writeProfileFiles(t *testing.T) string {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"app.env": `NAME=app
+PROFILE=staging
+LEVEL=INFO
+PORT=80
+PROFILES_PROD_LEVEL=ERROR
+PROFILES_STAGING_LEVEL=WARN
+PROFILES_PROD_PORT=8443
+`,
+		"app.prod.env":    "PORT=443\nREPLICAS=3\n",
+		"app.staging.env": "REPLICAS=1\n",
+	})
+	return path.Join(dir, "app.env")
+}
+
+func TestLoadProfile(t *testing.T) {
+	ctx := context.TODO()
+	file := writeProfileFiles(t)
+	config, err := NewLoadedConfig(ctx, nil, []string{file}, WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "prod" {
+		t.Errorf("Expected profile prod, got '%s'", config.Profile())
+	}
+	// the subtree overlay is applied after the sibling file
+	expected := map[string]string{
+		"NAME":     "app",
+		"LEVEL":    "ERROR",
+		"PORT":     "8443",
+		"REPLICAS": "3",
+	}
+	if err := config.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadProfileFromEnv(t *testing.T) {
+	ctx := context.TODO()
+	file := writeProfileFiles(t)
+	t.Setenv("GOTILS_TEST_PROFILE", "prod")
+	config, err := NewLoadedConfig(ctx, nil, []string{file}, WithProfileEnv("GOTILS_TEST_PROFILE"), WithProfileKey("PROFILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "prod" {
+		t.Errorf("Expected profile prod, got '%s'", config.Profile())
+	}
+}
+
+func TestLoadProfileFromKey(t *testing.T) {
+	ctx := context.TODO()
+	file := writeProfileFiles(t)
+	config, err := NewLoadedConfig(ctx, nil, []string{file}, WithProfileKey("PROFILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "staging" {
+		t.Errorf("Expected profile staging, got '%s'", config.Profile())
+	}
+	expected := map[string]string{
+		"LEVEL":    "WARN",
+		"PORT":     "80",
+		"REPLICAS": "1",
+	}
+	if err := config.CompareMap(ctx, expected, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadWithoutProfile(t *testing.T) {
+	ctx := context.TODO()
+	file := writeProfileFiles(t)
+	config, err := NewLoadedConfig(ctx, nil, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "" {
+		t.Errorf("Expected no profile, got '%s'", config.Profile())
+	}
+	if config.Has(ctx, "REPLICAS") {
+		t.Error("Profile file loaded without profile")
+	}
+	if val, err := config.Get(ctx, "LEVEL"); err != nil || val != "INFO" {
+		t.Errorf("Expected base level, got '%s' (%v)", val, err)
+	}
+}
+
+// countingLoader counts the loads of the files
+type countingLoader struct {
+	ConfigLoader
+	loads int
+}
+
+func (l *countingLoader) LoadFile(ctx context.Context, store ConfigStore, paths []string) error {
+	l.loads++
+	return l.ConfigLoader.LoadFile(ctx, store, paths)
+}
+
+func TestLoadProfileKeyMissing(t *testing.T) {
+	ctx := context.TODO()
+	file := writeProfileFiles(t)
+	loader := &countingLoader{}
+	config, err := NewLoadedConfig(ctx, nil, []string{file}, WithLoader(loader), WithProfileKey("MISSING"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Profile() != "" || loader.loads != 1 {
+		t.Errorf("Expected a single load without profile, got %d loads (profile '%s')", loader.loads, config.Profile())
+	}
+	if val, err := config.Get(ctx, "LEVEL"); err != nil || val != "INFO" {
+		t.Errorf("Expected base level, got '%s' (%v)", val, err)
+	}
+}
+
+func TestLoadProfileInvalid(t *testing.T) {
+	file := writeProfileFiles(t)
+	if _, err := NewLoadedConfig(context.TODO(), nil, []string{file}, WithProfile("../prod")); err == nil {
+		t.Error("Expected invalid profile to fail")
+	}
+}
+
+func TestLoadProfileConfDir(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"conf.d/10-app.env":       "LEVEL=INFO\nPORT=80\n",
+		"conf.d/10-app.dev.env":   "LEVEL=DEBUG\n",
+		"conf.d/10-app.prod.env":  "LEVEL=ERROR\n",
+		"conf.d/20-port.env":      "PORT=8080\n",
+		"conf.d/30-extra.dev.env": "EXTRA=dev\n",
+	})
+	confDir := path.Join(dir, "conf.d")
+	config, err := NewLoadedConfig(ctx, nil, []string{confDir}, WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"LEVEL": "ERROR", "PORT": "8080"}, true); err != nil {
+		t.Error(err)
+	}
+
+	config, err = NewLoadedConfig(ctx, nil, []string{confDir}, WithProfile("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"LEVEL": "DEBUG", "PORT": "8080", "EXTRA": "dev"}, true); err != nil {
+		t.Error(err)
+	}
+
+	config, err = NewLoadedConfig(ctx, nil, []string{confDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"LEVEL": "INFO", "PORT": "8080"}, true); err != nil {
+		t.Error(err)
+	}
+}
+
+// plainLoader is a loader that is not a ConfigLoader
+type plainLoader struct {
+	Loader
+}
+
+func TestLoadProfileCustomLoader(t *testing.T) {
+	file := writeProfileFiles(t)
+	loader := plainLoader{Loader: &ConfigLoader{}}
+	_, err := NewLoadedConfig(context.TODO(), nil, []string{file}, WithLoader(loader), WithProfile("prod"))
+	if !errors.Is(err, ErrProfileUnsupported) {
+		t.Errorf("Expected unsupported profile error, got %v", err)
+	}
+	if _, err := NewLoadedConfig(context.TODO(), nil, []string{file}, WithLoader(loader)); err != nil {
+		t.Errorf("Expected custom loader without profile to load, got %v", err)
+	}
+}
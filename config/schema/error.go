@@ -0,0 +1,31 @@
+package schema
+
+import "github.com/myLogic207/gotils/config"
+
+type ErrSchemaInvalid struct {
+	nested error
+}
+
+func (e *ErrSchemaInvalid) Error() string {
+	return "invalid schema: " + e.nested.Error()
+}
+
+func (e *ErrSchemaInvalid) Unwrap() error {
+	return config.ErrLoadingConfig
+}
+
+type ErrSchemaViolation struct {
+	key    string
+	reason string
+}
+
+func (e *ErrSchemaViolation) Error() string {
+	if e.key == "" {
+		return "schema violation: " + e.reason
+	}
+	return "schema violation at " + e.key + ": " + e.reason
+}
+
+func (e *ErrSchemaViolation) Unwrap() error {
+	return config.ErrValueInvalid
+}
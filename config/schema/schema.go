@@ -0,0 +1,186 @@
+// Package schema validates configs against a subset of JSON Schema
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/myLogic207/gotils/config"
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// Schema describes the expected layout of a config, it is a subset of JSON Schema.
+// Property names are matched case insensitive, as config keys are upper case.
+// Values are validated in their text form, so "integer" accepts any value parsed by strconv.ParseInt.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+}
+
+var schemaTypes = []string{"", "object", "array", "string", "integer", "number", "boolean"}
+
+// Load reads a JSON schema and checks its types and patterns
+func Load(r io.Reader) (*Schema, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	schema := &Schema{}
+	if err := decoder.Decode(schema); err != nil {
+		return nil, &ErrSchemaInvalid{nested: err}
+	}
+	if err := schema.check(""); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (s *Schema) check(path string) error {
+	if !slices.Contains(schemaTypes, s.Type) {
+		return &ErrSchemaInvalid{nested: fmt.Errorf("unsupported type %q at %q", s.Type, path)}
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return &ErrSchemaInvalid{nested: fmt.Errorf("invalid pattern at %q: %w", path, err)}
+		}
+	}
+	for name, property := range s.Properties {
+		if property == nil {
+			continue
+		}
+		if err := property.check(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.check(path + "/items")
+	}
+	return nil
+}
+
+// Validate checks all values of store against the schema, all violations are returned joined
+func (s *Schema) Validate(ctx context.Context, store config.ConfigStore) error {
+	tree := buildTree(ctx, store)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	violations := []error{}
+	s.validate(tree, &violations)
+	return errors.Join(violations...)
+}
+
+func (s *Schema) validate(node *keytree.Tree, violations *[]error) {
+	violate := func(reason string) {
+		*violations = append(*violations, &ErrSchemaViolation{key: node.Key, reason: reason})
+	}
+	switch s.Type {
+	case "object":
+		if node.HasValue && len(node.Children) == 0 {
+			violate("expected an object")
+			return
+		}
+	case "array":
+		if node.HasValue && len(node.Children) == 0 || len(node.Children) > 0 && !node.IsList() {
+			violate("expected an array")
+			return
+		}
+	case "":
+	default:
+		if !node.HasValue {
+			violate("expected a value of type " + s.Type)
+			return
+		}
+	}
+	if node.HasValue {
+		if reason := s.validateValue(node.Value); reason != "" {
+			violate(reason)
+		}
+	}
+	s.validateChildren(node, violations)
+}
+
+func (s *Schema) validateChildren(node *keytree.Tree, violations *[]error) {
+	properties := make(map[string]*Schema, len(s.Properties))
+	for name, property := range s.Properties {
+		properties[strings.ToUpper(name)] = property
+	}
+	for _, name := range s.Required {
+		if _, ok := node.Children[strings.ToUpper(name)]; !ok {
+			*violations = append(*violations, &ErrSchemaViolation{key: keytree.Join(node.Key, strings.ToUpper(name)), reason: "required key is missing"})
+		}
+	}
+	for _, name := range node.ChildNames() {
+		child := node.Children[name]
+		property, known := properties[name]
+		switch {
+		case known && property != nil:
+			property.validate(child, violations)
+		case known:
+		case s.Items != nil && node.IsList():
+			s.Items.validate(child, violations)
+		case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+			*violations = append(*violations, &ErrSchemaViolation{key: child.Key, reason: "key is not allowed"})
+		}
+	}
+}
+
+// validateValue returns the reason a value violates the schema, or an empty string
+func (s *Schema) validateValue(value string) string {
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "expected an integer, got " + strconv.Quote(value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "expected a number, got " + strconv.Quote(value)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "expected a boolean, got " + strconv.Quote(value)
+		}
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed any) bool { return fmt.Sprint(allowed) == value }) {
+		return fmt.Sprintf("value %q is not one of %v", value, s.Enum)
+	}
+	if s.Pattern != "" {
+		if matched, err := regexp.MatchString(s.Pattern, value); err != nil || !matched {
+			return fmt.Sprintf("value %q does not match pattern %s", value, s.Pattern)
+		}
+	}
+	if s.Minimum != nil || s.Maximum != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		switch {
+		case err != nil:
+			return "expected a number, got " + strconv.Quote(value)
+		case s.Minimum != nil && number < *s.Minimum:
+			return fmt.Sprintf("value %s is less than minimum %v", value, *s.Minimum)
+		case s.Maximum != nil && number > *s.Maximum:
+			return fmt.Sprintf("value %s is greater than maximum %v", value, *s.Maximum)
+		}
+	}
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		return fmt.Sprintf("value is shorter than %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Sprintf("value is longer than %d characters", *s.MaxLength)
+	}
+	return ""
+}
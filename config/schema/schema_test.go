@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/myLogic207/gotils/config"
+)
+
+const testSchema = `{
+	"type": "object",
+	"required": ["name", "server"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+		"level": {"type": "string", "enum": ["debug", "info"]},
+		"server": {
+			"type": "object",
+			"required": ["port"],
+			"properties": {
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+				"tls": {"type": "boolean"}
+			}
+		},
+		"hosts": {"type": "array", "items": {"type": "string", "maxLength": 5}}
+	}
+}`
+
+func TestSchemaValid(t *testing.T) {
+	schema, err := Load(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.WithInitialValues(context.TODO(), map[string]interface{}{
+		"name":   "app",
+		"level":  "info",
+		"server": map[string]interface{}{"port": 8080, "tls": "true"},
+		"hosts":  []interface{}{"alpha", "beta"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(context.TODO(), cfg); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSchemaViolations(t *testing.T) {
+	schema, err := Load(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.WithInitialValues(context.TODO(), map[string]interface{}{
+		"name":    "A",
+		"level":   "trace",
+		"server":  map[string]interface{}{"port": "99999", "tls": "maybe"},
+		"hosts":   []interface{}{"alpha", "toolong"},
+		"unknown": "x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = schema.Validate(context.TODO(), cfg)
+	if !errors.Is(err, config.ErrValueInvalid) {
+		t.Fatalf("Expected violations, got %v", err)
+	}
+	for _, key := range []string{"NAME", "LEVEL", "SERVER/PORT", "SERVER/TLS", "HOSTS/1", "UNKNOWN"} {
+		if !strings.Contains(err.Error(), "at "+key+":") {
+			t.Errorf("Expected violation for %s in:\n%v", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), "HOSTS/0") {
+		t.Errorf("Unexpected violation for HOSTS/0 in:\n%v", err)
+	}
+
+	missing, err := config.WithInitialValues(context.TODO(), map[string]interface{}{"server": map[string]interface{}{"tls": "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = schema.Validate(context.TODO(), missing)
+	for _, key := range []string{"NAME", "SERVER/PORT"} {
+		if err == nil || !strings.Contains(err.Error(), "at "+key+": required key is missing") {
+			t.Errorf("Expected missing %s, got %v", key, err)
+		}
+	}
+}
+
+func TestLoadSchemaInvalid(t *testing.T) {
+	schemas := []string{
+		`{"type": "map"}`,
+		`{"properties": {"a": {"pattern": "("}}}`,
+		`{"type": 1}`,
+	}
+	for _, raw := range schemas {
+		var invalid *ErrSchemaInvalid
+		if _, err := Load(strings.NewReader(raw)); !errors.As(err, &invalid) {
+			t.Errorf("%s: expected invalid schema, got %v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/myLogic207/gotils/config"
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// buildTree reads all keys of store into a tree
+func buildTree(ctx context.Context, store config.ConfigStore) *keytree.Tree {
+	values := make(map[string]string)
+	for _, key := range store.Keys(ctx) {
+		if value, ok := store.GetAll(ctx, key)[""]; ok {
+			values[key] = value
+		}
+	}
+	return keytree.Build("", values)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DEFAULT_SECRET_MAX_SIZE int64 = 1 << 20 // 1 MiB
+	SECRET_HIDDEN_PREFIX          = "."
+)
+
+type SecretDirOptions struct {
+	// Prefix is prepended to every key loaded from the directory
+	Prefix string
+	// TrimNewline removes a single trailing line break from every file
+	TrimNewline bool
+	// MaxSize is the maximum size of a single file in bytes, defaults to DEFAULT_SECRET_MAX_SIZE
+	MaxSize int64
+}
+
+// LoadSecretDir maps every file in a directory (e.g. /run/secrets or a mounted ConfigMap) to a key.
+// The file name is used as key, with '_' and '.' treated as tree separators, sub directories are nested.
+// Contents are loaded byte-exact unless TrimNewline is set.
+// Files whose name is not a valid key are skipped. Hidden entries are skipped as well, this includes
+// the '..data' and timestamped directories Kubernetes uses for atomic updates, the visible symlinks
+// pointing into them are followed instead.
+func (cl *ConfigLoader) LoadSecretDir(ctx context.Context, store ConfigStore, dir string, options *SecretDirOptions) error {
+	if options == nil {
+		options = &SecretDirOptions{}
+	}
+	maxSize := options.MaxSize
+	if maxSize <= 0 {
+		maxSize = DEFAULT_SECRET_MAX_SIZE
+	}
+	baseKey := strings.Trim(strings.ToUpper(options.Prefix), CONFIG_TREE_SEPARATOR)
+	eg, eCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return loadSecretDir(eCtx, eg, store, dir, baseKey, maxSize, options.TrimNewline)
+	})
+	return eg.Wait()
+}
+
+func loadSecretDir(ctx context.Context, errGroup *errgroup.Group, store ConfigStore, dir string, baseKey string, maxSize int64, trim bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), SECRET_HIDDEN_PREFIX) {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		key := secretKey(baseKey, entry.Name())
+		// stat follows symlinks, so links into the current '..data' generation resolve
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			errGroup.Go(func() error {
+				return loadSecretDir(ctx, errGroup, store, filePath, key, maxSize, trim)
+			})
+			continue
+		} else if !info.Mode().IsRegular() {
+			continue
+		}
+		if err := IsValidKey(key); err != nil {
+			// files like "ca bundle.pem" next to the secrets do not abort the load
+			continue
+		}
+		errGroup.Go(func() error {
+			content, err := readSecretFile(osFiles{}, filePath, maxSize)
+			if err != nil {
+				return err
+			}
+			if trim {
+				content = trimNewline(content)
+			}
+			return setIfAbsent(ctx, store, key, string(content))
+		})
+	}
+	return nil
+}
+
+func secretKey(baseKey string, name string) string {
+	segment := strings.ToUpper(name)
+	segment = strings.ReplaceAll(segment, ".", CONFIG_TREE_SEPARATOR)
+	segment = strings.ReplaceAll(segment, ENV_SPLIT_CHAR, CONFIG_TREE_SEPARATOR)
+	if baseKey == "" {
+		return segment
+	}
+	return baseKey + CONFIG_TREE_SEPARATOR + segment
+}
+
+// readSecretFile reads a file byte-exact, failing if it is larger than maxSize
+func readSecretFile(files fileSource, path string, maxSize int64) ([]byte, error) {
+	name, err := files.abs(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := files.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil {
+		return nil, err
+	} else if info.Size() > maxSize {
+		return nil, &ErrSecretTooLarge{path: path, limit: maxSize}
+	}
+	// the reported size is not reliable for every file (e.g. in /proc), so limit the read as well
+	content, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, &ErrSecretTooLarge{path: path, limit: maxSize}
+	}
+	return content, nil
+}
+
+// trimNewline removes a trailing "\n" or "\r\n", a lone "\r" is part of the content
+func trimNewline(content []byte) []byte {
+	if trimmed, ok := bytes.CutSuffix(content, []byte("\n")); ok {
+		return bytes.TrimSuffix(trimmed, []byte("\r"))
+	}
+	return content
+}
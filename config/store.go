@@ -9,8 +9,15 @@ import (
 
 type ConfigStore interface {
 	Get(ctx context.Context, key string) (string, error)
+	// GetAll returns the values of key and the keys nested below it, matching whole segments (DB does not match DBX/PORT)
 	GetAll(ctx context.Context, key string) map[string]string
+	// Set stores a value, without force an existing key is not overwritten and ErrKeyInStore is returned
 	Set(ctx context.Context, key string, value string, force bool) error
+	// Delete removes a single key, returning ErrKeyNotFound if it does not exist
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes a key and all keys nested below it
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Has checks if key or a key nested below it exists, matching whole segments like GetAll
 	Has(ctx context.Context, key string) bool
 	// HasAllKeys(cmp map[string]interface{}) error
 	Keys(ctx context.Context) []string
@@ -18,6 +25,9 @@ type ConfigStore interface {
 
 type ConfigStoreNew func(context.Context) (ConfigStore, error)
 
+// DefaultConfigStore creates the stores of configs created without WithStore.
+//
+// Deprecated: the variable is shared by all configs of the process, pass WithStore to the constructors instead.
 var DefaultConfigStore ConfigStoreNew = NewConfigStore
 
 func NewConfigStore(ctx context.Context) (ConfigStore, error) {
@@ -31,6 +41,16 @@ func NewConfigStore(ctx context.Context) (ConfigStore, error) {
 type ConfigStoreImpl struct {
 	mu    sync.RWMutex
 	store map[string]string
+	// typed holds the original value of keys set with SetTyped, next to the string form in store
+	typed map[string]any
+	// shared is set while the maps are referenced by a snapshot, they are copied before the next write
+	shared bool
+	// readers counts the running transactions reading the current maps, they are copied before the next write as well
+	readers      int
+	version      uint64
+	oldest       uint64
+	history      []change
+	historyLimit int
 }
 
 func (c *ConfigStoreImpl) Has(ctx context.Context, key string) bool {
@@ -41,16 +61,7 @@ func (c *ConfigStoreImpl) Has(ctx context.Context, key string) bool {
 	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	for k := range c.store {
-		if err := ctx.Err(); err != nil {
-			// context Cancelled
-			return false
-		}
-		if strings.HasPrefix(k, key) {
-			return true
-		}
-	}
-	return false
+	return storeMap(c.store).has(ctx, key)
 }
 
 func (c *ConfigStoreImpl) HasAllKeys(ctx context.Context, cmp []string) error {
@@ -69,15 +80,9 @@ func (c *ConfigStoreImpl) Get(ctx context.Context, key string) (string, error) {
 	if err := IsValidKey(key); err != nil { // check key is valid
 		return "", err
 	}
-	if !c.Has(ctx, key) {
-		return "", &ErrKeyNotFound{key: key}
-	}
-	matchedValues := c.GetAll(ctx, key)
-	if len(matchedValues) == 1 {
-		return matchedValues[""], nil
-	} else {
-		return "", &ErrKeyAmbiguous{key: key}
-	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return storeMap(c.store).get(ctx, key)
 }
 
 // GetAll returns all values that match the given key.
@@ -87,22 +92,121 @@ func (c *ConfigStoreImpl) Get(ctx context.Context, key string) (string, error) {
 // (the part of the key after the last CONFIG_TREE_SEPARATOR)
 // Single values are therefore indexed by an empty string.
 func (c *ConfigStoreImpl) GetAll(ctx context.Context, key string) map[string]string {
-	values := make(map[string]string)
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	for k, v := range c.store {
+	return storeMap(c.store).getAll(ctx, key)
+}
+
+// matchesKey checks if storeKey is the key itself or nested below it.
+// Keys only match whole segments, a plain prefix check would match DBX/PORT for DB.
+func matchesKey(storeKey string, key string) bool {
+	return storeKey == key || strings.HasPrefix(storeKey, key+CONFIG_TREE_SEPARATOR)
+}
+
+func (c *ConfigStoreImpl) Set(ctx context.Context, key string, value string, force bool) error {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.store[key]; ok && !force {
+		return &ErrKeyInStore{key: key}
+	}
+	c.commit([]change{{key: key, value: value}})
+	return nil
+}
+
+func (c *ConfigStoreImpl) Delete(ctx context.Context, key string) error {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.store[key]; !ok {
+		return &ErrKeyNotFound{key: key}
+	}
+	c.commit([]change{{key: key, deleted: true}})
+	return nil
+}
+
+func (c *ConfigStoreImpl) DeletePrefix(ctx context.Context, prefix string) error {
+	prefix = strings.TrimSpace(prefix)
+	prefix = strings.ToUpper(prefix)
+	if err := IsValidKey(prefix); err != nil { // check key is valid
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changes := []change{}
+	for key := range c.store {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if matchesKey(key, prefix) {
+			changes = append(changes, change{key: key, deleted: true})
+		}
+	}
+	c.commit(changes)
+	return nil
+}
+
+func (c *ConfigStoreImpl) Keys(ctx context.Context) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return storeMap(c.store).keys(ctx)
+}
+
+// CompareKeys orders keys segment by segment, so DB/PORT sorts before DB-REPLICA/PORT
+func CompareKeys(a string, b string) int {
+	return slices.Compare(strings.Split(a, CONFIG_TREE_SEPARATOR), strings.Split(b, CONFIG_TREE_SEPARATOR))
+}
+
+// storeMap implements the lookups on a plain map, callers are responsible for locking
+type storeMap map[string]string
+
+func (m storeMap) has(ctx context.Context, key string) bool {
+	for k := range m {
+		if err := ctx.Err(); err != nil {
+			// context Cancelled
+			return false
+		}
+		if matchesKey(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m storeMap) get(ctx context.Context, key string) (string, error) {
+	if !m.has(ctx, key) {
+		return "", &ErrKeyNotFound{key: key}
+	}
+	matchedValues := m.getAll(ctx, key)
+	if len(matchedValues) == 1 {
+		return matchedValues[""], nil
+	} else {
+		return "", &ErrKeyAmbiguous{key: key}
+	}
+}
+
+func (m storeMap) getAll(ctx context.Context, key string) map[string]string {
+	values := make(map[string]string)
+	for k, v := range m {
 		if err := ctx.Err(); err != nil {
 			// context Cancelled, return what we have
 			return values
 		}
-		if !strings.HasPrefix(k, key) {
+		if !matchesKey(k, key) {
 			continue
 		}
 		trimKey := strings.TrimPrefix(k, key)
-		// if trimKey == "" || len(trimKey) == 1 && string(trimKey[0]) == CONFIG_TREE_SEPARATOR {
-		// 	println("direct match:", k)
-		// 	return v
-		// }
 		trimKey = strings.TrimPrefix(trimKey, CONFIG_TREE_SEPARATOR)
 		values[trimKey] = v
 	}
@@ -112,30 +216,15 @@ func (c *ConfigStoreImpl) GetAll(ctx context.Context, key string) map[string]str
 	return values
 }
 
-func (c *ConfigStoreImpl) Set(ctx context.Context, key string, value string, force bool) error {
-	key = strings.TrimSpace(key)
-	key = strings.ToUpper(key)
-
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if value == "" {
-		delete(c.store, key) // delete key if value is nil or empty
-	} else {
-		c.store[key] = value
-	}
-	return nil
-}
-
-func (c *ConfigStoreImpl) Keys(ctx context.Context) []string {
+func (m storeMap) keys(ctx context.Context) []string {
 	keys := []string{}
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	for key := range c.store {
+	for key := range m {
 		if err := ctx.Err(); err != nil {
 			// context Cancelled, return what we have
 			return keys
 		}
 		keys = append(keys, key)
 	}
+	slices.SortFunc(keys, CompareKeys)
 	return keys
 }
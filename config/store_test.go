@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sync"
 	"testing"
 )
@@ -21,7 +23,7 @@ func TestDelete(t *testing.T) {
 		t.Error("Config is not loaded correctly (level 0)")
 	}
 
-	if err := store.Set(ctx, "SIMPLE", "", true); err != nil {
+	if err := store.Delete(ctx, "SIMPLE"); err != nil {
 		t.Error(err)
 	}
 
@@ -48,14 +50,150 @@ func TestSet(t *testing.T) {
 		t.Errorf("Expected 'TEST_VALUE', got '%v'", value)
 	}
 
-	err = store.Set(context.Background(), "TEST_KEY", "", false)
+	err = store.Set(context.Background(), "TEST_KEY", "OTHER", false)
+	if !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected key in store error, got %v", err)
+	}
+
+	err = store.Set(context.Background(), "TEST_KEY", "", true)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	_, ok = store.store["TEST_KEY"]
-	if ok {
-		t.Errorf("Expected key to be deleted")
+	value, ok = store.store["TEST_KEY"]
+	if !ok || value != "" {
+		t.Errorf("Expected empty value to be stored, got '%v' (%v)", value, ok)
+	}
+}
+
+// storeImplementations returns a fresh instance of every writable store
+func storeImplementations(t *testing.T) map[string]ConfigStore {
+	ctx := context.TODO()
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]ConfigStore{
+		"ConfigStoreImpl": store,
+		"Config":          config,
+		"plain":           &Config{ConfigStore: plainStore{plain}},
+		"View":            parent.View("SCOPE"),
+	}
+}
+
+func TestSetForce(t *testing.T) {
+	ctx := context.TODO()
+	for name, store := range storeImplementations(t) {
+		if err := store.Set(ctx, "A", "1", false); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if err := store.Set(ctx, "A", "2", false); !errors.Is(err, ErrConfigKey) {
+			t.Errorf("%s: expected key in store error, got %v", name, err)
+		}
+		if value, _ := store.Get(ctx, "A"); value != "1" {
+			t.Errorf("%s: value was overwritten without force, got '%s'", name, value)
+		}
+		if err := store.Set(ctx, "A", "2", true); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if value, _ := store.Get(ctx, "A"); value != "2" {
+			t.Errorf("%s: expected '2', got '%s'", name, value)
+		}
+		if err := store.Set(ctx, "A.B", "x", true); !errors.Is(err, ErrValueInvalid) {
+			t.Errorf("%s: expected invalid key error, got %v", name, err)
+		}
+	}
+}
+
+func TestSetEmptyValue(t *testing.T) {
+	ctx := context.TODO()
+	for name, store := range storeImplementations(t) {
+		if err := store.Set(ctx, "EMPTY", "", false); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !store.Has(ctx, "EMPTY") {
+			t.Errorf("%s: empty value was not stored", name)
+		}
+		if value, err := store.Get(ctx, "EMPTY"); err != nil || value != "" {
+			t.Errorf("%s: expected empty value, got '%s' (%v)", name, value, err)
+		}
+	}
+}
+
+func TestDeleteKey(t *testing.T) {
+	ctx := context.TODO()
+	for name, store := range storeImplementations(t) {
+		for _, key := range []string{"A", "A/B", "AB"} {
+			if err := store.Set(ctx, key, "1", true); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if err := store.Delete(ctx, "A"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if value, err := store.Get(ctx, "A/B"); err != nil || value != "1" {
+			t.Errorf("%s: delete removed nested key, got '%s' (%v)", name, value, err)
+		}
+		if err := store.Delete(ctx, "A"); !errors.Is(err, ErrConfigKey) {
+			t.Errorf("%s: expected key not found error, got %v", name, err)
+		}
+		if !slices.Equal(store.Keys(ctx), []string{"A/B", "AB"}) {
+			t.Errorf("%s: unexpected keys after delete %v", name, store.Keys(ctx))
+		}
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	ctx := context.TODO()
+	for name, store := range storeImplementations(t) {
+		for _, key := range []string{"A", "A/B", "A/B/C", "AB"} {
+			if err := store.Set(ctx, key, "1", true); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if err := store.DeletePrefix(ctx, "a"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if !slices.Equal(store.Keys(ctx), []string{"AB"}) {
+			t.Errorf("%s: unexpected keys after delete %v", name, store.Keys(ctx))
+		}
+		// deleting a missing prefix is not an error
+		if err := store.DeletePrefix(ctx, "MISSING"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestDeletePrefixSingleVersion(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": map[string]interface{}{"b": "1", "c": "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := config.Version()
+	if err := config.DeletePrefix(ctx, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version() != version+1 {
+		t.Errorf("Expected a single version for the delete, got %d -> %d", version, config.Version())
+	}
+	if err := config.Rollback(ctx, version); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A/B": "1", "A/C": "2"}, true); err != nil {
+		t.Error(err)
 	}
 }
 
@@ -104,3 +242,44 @@ func TestGet(t *testing.T) {
 		t.Errorf("Expected error for non-existent key, got nil")
 	}
 }
+
+func TestMatchesKey(t *testing.T) {
+	for _, test := range []struct {
+		storeKey string
+		key      string
+		matches  bool
+	}{
+		{"DB", "DB", true},
+		{"DB/PORT", "DB", true},
+		{"DB/PORT/NUMBER", "DB", true},
+		{"DBX/PORT", "DB", false},
+		{"DB/PORTS", "DB/PORT", false},
+		{"DB", "DB/PORT", false},
+		{"D", "DB", false},
+	} {
+		if matchesKey(test.storeKey, test.key) != test.matches {
+			t.Errorf("Expected match of %s for %s to be %t", test.storeKey, test.key, test.matches)
+		}
+	}
+}
+
+func TestGetSegmentBoundary(t *testing.T) {
+	store := &ConfigStoreImpl{
+		mu: sync.RWMutex{},
+		store: map[string]string{
+			"DB":         "main",
+			"DBX/PORT":   "6543",
+			"NESTED/KEY": "nested",
+		},
+	}
+	ctx := context.Background()
+	if value, err := store.Get(ctx, "DB"); err != nil || value != "main" {
+		t.Errorf("Expected 'main', got '%v' (%v)", value, err)
+	}
+	if !store.Has(ctx, "DBX") || store.Has(ctx, "D") || store.Has(ctx, "NEST") {
+		t.Error("Expected Has to match whole segments")
+	}
+	if values := store.GetAll(ctx, "DB"); len(values) != 1 || values[""] != "main" {
+		t.Errorf("Expected only DB, got %v", values)
+	}
+}
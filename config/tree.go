@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// WalkFunc is called for every node visited by Walk.
+// Nodes without a value of their own (only holding children) have hasValue set to false.
+// Returning ErrSkipTree skips the children of the node, any other error stops the walk.
+type WalkFunc func(key string, value string, hasValue bool) error
+
+// Children returns the sorted, distinct child segments directly below prefix.
+// An empty prefix returns the top level segments.
+func (c *Config) Children(ctx context.Context, prefix string) []string {
+	prefix = normalizePrefix(prefix)
+	children := []string{}
+	for _, key := range c.Keys(ctx) {
+		rest, ok := keytree.CutPrefix(key, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		segment, _, _ := strings.Cut(rest, CONFIG_TREE_SEPARATOR)
+		children = append(children, segment)
+	}
+	slices.Sort(children)
+	return slices.Compact(children)
+}
+
+// Walk visits the tree below prefix depth-first, parents before their children and
+// siblings in sorted order. The prefix node itself is visited first, unless it is the root.
+func (c *Config) Walk(ctx context.Context, prefix string, fn WalkFunc) error {
+	prefix = normalizePrefix(prefix)
+	if prefix != "" && !c.Has(ctx, prefix) {
+		return &ErrKeyNotFound{key: prefix}
+	}
+	values, err := c.values(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err = walkTree(keytree.Build(prefix, values), fn, prefix != "")
+	if errors.Is(err, ErrSkipTree) {
+		return nil
+	}
+	return err
+}
+
+// values returns all values at or below prefix, indexed by their full key.
+// Errors of computed keys are returned, unlike GetAll they are not left out.
+func (c *Config) values(ctx context.Context, prefix string) (map[string]string, error) {
+	values := collectValues(ctx, c.ConfigStore, prefix)
+	if c.computed == nil {
+		return values, nil
+	}
+	prefixes := []string{prefix}
+	if prefix == "" {
+		// computed keys are matched by their top level segment
+		prefixes = []string{}
+		for _, key := range c.computedKeyList() {
+			segment, _, _ := strings.Cut(key, CONFIG_TREE_SEPARATOR)
+			prefixes = append(prefixes, segment)
+		}
+		slices.Sort(prefixes)
+		prefixes = slices.Compact(prefixes)
+	}
+	errs := []error{}
+	for _, p := range prefixes {
+		computed, err := c.computedValues(ctx, p)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for suffix, value := range computed {
+			values[keytree.Join(p, suffix)] = value
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+// collectValues reads all values at or below prefix from a store, indexed by their full key
+func collectValues(ctx context.Context, store ConfigStore, prefix string) map[string]string {
+	values := make(map[string]string)
+	prefixes := []string{prefix}
+	if prefix == "" {
+		prefixes = []string{}
+		for _, key := range store.Keys(ctx) {
+			segment, _, _ := strings.Cut(key, CONFIG_TREE_SEPARATOR)
+			prefixes = append(prefixes, segment)
+		}
+		slices.Sort(prefixes)
+		prefixes = slices.Compact(prefixes)
+	}
+	for _, p := range prefixes {
+		for suffix, value := range store.GetAll(ctx, p) {
+			values[keytree.Join(p, suffix)] = value
+		}
+	}
+	return values
+}
+
+// walkTree visits t and the nodes below it, siblings in sorted order
+func walkTree(t *keytree.Tree, fn WalkFunc, visitSelf bool) error {
+	if visitSelf {
+		if err := fn(t.Key, t.Value, t.HasValue); errors.Is(err, ErrSkipTree) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(t.Children))
+	for name := range t.Children {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := walkTree(t.Children[name], fn, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizePrefix(prefix string) string {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	return strings.Trim(prefix, CONFIG_TREE_SEPARATOR)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func newTreeTestConfig(t *testing.T) *Config {
+	config, err := WithInitialValues(context.TODO(), map[string]interface{}{
+		"name": "tree",
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"port": 80,
+				"host": "localhost",
+			},
+			"db": map[string]interface{}{
+				"port": 5432,
+			},
+			"db-replica": map[string]interface{}{
+				"port": 5433,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestSortedOutput(t *testing.T) {
+	config := newTreeTestConfig(t)
+	expected := `NAME: tree
+SERVICES/DB/PORT: 5432
+SERVICES/DB-REPLICA/PORT: 5433
+SERVICES/WEB/HOST: localhost
+SERVICES/WEB/PORT: 80
+`
+	for i := 0; i < 5; i++ {
+		if output := config.Sprint(); output != expected {
+			t.Fatalf("Unexpected output:\n%s", output)
+		}
+	}
+	if keys := config.Keys(context.TODO()); !slices.IsSortedFunc(keys, CompareKeys) {
+		t.Errorf("Keys are not sorted: %v", keys)
+	}
+	// a config value is a store as well
+	var store ConfigStore = *config
+	if keys := store.Keys(context.TODO()); len(keys) != 5 {
+		t.Errorf("Unexpected keys of config value %v", keys)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	config := newTreeTestConfig(t)
+	ctx := context.TODO()
+	cases := map[string][]string{
+		"":              {"NAME", "SERVICES"},
+		"services":      {"DB", "DB-REPLICA", "WEB"},
+		"SERVICES/WEB/": {"HOST", "PORT"},
+		"SERVICES/DB":   {"PORT"},
+		"NAME":          {},
+		"MISSING":       {},
+		"SERV":          {},
+	}
+	for prefix, expected := range cases {
+		if children := config.Children(ctx, prefix); !slices.Equal(children, expected) {
+			t.Errorf("Children of '%s': expected %v, got %v", prefix, expected, children)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	config := newTreeTestConfig(t)
+	ctx := context.TODO()
+	visited := []string{}
+	walkFn := func(key string, value string, hasValue bool) error {
+		visited = append(visited, fmt.Sprintf("%s=%s(%t)", key, value, hasValue))
+		return nil
+	}
+	if err := config.Walk(ctx, "", walkFn); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"NAME=tree(true)",
+		"SERVICES=(false)",
+		"SERVICES/DB=(false)",
+		"SERVICES/DB/PORT=5432(true)",
+		"SERVICES/DB-REPLICA=(false)",
+		"SERVICES/DB-REPLICA/PORT=5433(true)",
+		"SERVICES/WEB=(false)",
+		"SERVICES/WEB/HOST=localhost(true)",
+		"SERVICES/WEB/PORT=80(true)",
+	}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("Unexpected walk order:\n%v", visited)
+	}
+
+	visited = []string{}
+	if err := config.Walk(ctx, "services/web", walkFn); err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		"SERVICES/WEB=(false)",
+		"SERVICES/WEB/HOST=localhost(true)",
+		"SERVICES/WEB/PORT=80(true)",
+	}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("Unexpected walk order:\n%v", visited)
+	}
+
+	if err := config.Walk(ctx, "MISSING", walkFn); err == nil {
+		t.Error("Expected error walking missing prefix")
+	}
+}
+
+func TestWalkSkipTree(t *testing.T) {
+	config := newTreeTestConfig(t)
+	visited := []string{}
+	err := config.Walk(context.TODO(), "SERVICES", func(key string, value string, hasValue bool) error {
+		visited = append(visited, key)
+		if key == "SERVICES/DB" {
+			return ErrSkipTree
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"SERVICES", "SERVICES/DB", "SERVICES/DB-REPLICA", "SERVICES/DB-REPLICA/PORT", "SERVICES/WEB", "SERVICES/WEB/HOST", "SERVICES/WEB/PORT"}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("Unexpected walk order:\n%v", visited)
+	}
+
+	stopErr := fmt.Errorf("stop")
+	if err := config.Walk(context.TODO(), "", func(string, string, bool) error { return stopErr }); err != stopErr {
+		t.Errorf("Expected walk to stop with error, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Tx stages the changes of a transaction, reads see the staged changes.
+// Staged changes are applied together once the transaction function returns without error.
+type Tx interface {
+	Reader
+	Set(ctx context.Context, key string, value string) error
+	Delete(ctx context.Context, key string) error
+}
+
+// TxStore is implemented by stores that can apply a set of changes atomically
+type TxStore interface {
+	ConfigStore
+	// Update runs fn in a transaction, if fn returns an error no change is applied.
+	// Implementations must not hold locks while fn runs, fn may read the store.
+	Update(ctx context.Context, fn func(tx Tx) error) error
+}
+
+// Update applies all changes made in fn at once, if fn returns an error nothing is changed.
+// Stores implementing TxStore apply the changes atomically, for other stores the changes are
+// applied one by one and reverted if applying one fails.
+// fn may read the config, but writes have to go through tx. As fn can run more than once
+// if the store is changed concurrently, it should not have other side effects.
+func (c *Config) Update(ctx context.Context, fn func(tx Tx) error) error {
+	if err := c.checkFrozen(""); err != nil {
+		return err
+	}
+	if c.aliases != nil {
+		update := fn
+		fn = func(tx Tx) error { return update(&aliasTx{tx: tx, config: c}) }
+	}
+	if c.computed != nil {
+		update := fn
+		fn = func(tx Tx) error { return update(&computedTx{tx: tx, config: c}) }
+	}
+	if !c.setHooks.empty() {
+		// wrapped after the aliases, so hooks get canonical keys
+		update := fn
+		fn = func(tx Tx) error {
+			hooked := &hookTx{tx: tx, config: c}
+			if err := update(hooked); err != nil {
+				return err
+			}
+			return hooked.apply()
+		}
+	}
+	if store, ok := c.ConfigStore.(TxStore); ok {
+		return store.Update(ctx, fn)
+	}
+	return updateStore(ctx, c.ConfigStore, fn)
+}
+
+// Update runs fn on a snapshot of the store and applies the staged changes as a single version,
+// readers never observe a partially applied transaction. The lock is only held to commit,
+// so fn can read the config. If the store changed while fn ran, fn is run again on the new state.
+func (c *ConfigStoreImpl) Update(ctx context.Context, fn func(tx Tx) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		if c.store == nil {
+			c.store = make(map[string]string)
+		}
+		// commits made while fn runs copy the map, so the view stays unchanged
+		c.readers++
+		view, version := c.store, c.version
+		c.mu.Unlock()
+
+		tx := &memoryTx{view: view, staged: make(map[string]change)}
+		err := fn(tx)
+		if err == nil {
+			err = ctx.Err()
+		}
+		c.mu.Lock()
+		if c.version == version {
+			// no commit copied the map, the view is still the current one
+			c.readers--
+		}
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		if c.version != version {
+			c.mu.Unlock()
+			continue
+		}
+		c.commit(tx.changes())
+		c.mu.Unlock()
+		return nil
+	}
+}
+
+// memoryTx stages changes for the in-memory store, the view is copied on the first write
+type memoryTx struct {
+	view   storeMap
+	copied bool
+	staged map[string]change
+	order  []string
+}
+
+func (tx *memoryTx) Get(ctx context.Context, key string) (string, error) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if err := IsValidKey(key); err != nil {
+		return "", err
+	}
+	return tx.view.get(ctx, key)
+}
+
+func (tx *memoryTx) Has(ctx context.Context, key string) bool {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if err := IsValidKey(key); err != nil {
+		return false
+	}
+	return tx.view.has(ctx, key)
+}
+
+func (tx *memoryTx) Set(ctx context.Context, key string, value string) error {
+	return tx.stage(change{key: key, value: value})
+}
+
+func (tx *memoryTx) Delete(ctx context.Context, key string) error {
+	return tx.stage(change{key: key, deleted: true})
+}
+
+func (tx *memoryTx) stage(ch change) error {
+	ch.key = strings.ToUpper(strings.TrimSpace(ch.key))
+	if err := IsValidKey(ch.key); err != nil { // check key is valid
+		return err
+	}
+	if !tx.copied {
+		view := make(storeMap, len(tx.view))
+		for key, value := range tx.view {
+			view[key] = value
+		}
+		tx.view = view
+		tx.copied = true
+	}
+	if ch.deleted {
+		delete(tx.view, ch.key)
+	} else {
+		tx.view[ch.key] = ch.value
+	}
+	if _, ok := tx.staged[ch.key]; !ok {
+		tx.order = append(tx.order, ch.key)
+	}
+	tx.staged[ch.key] = ch
+	return nil
+}
+
+func (tx *memoryTx) changes() []change {
+	changes := make([]change, 0, len(tx.order))
+	for _, key := range tx.order {
+		changes = append(changes, tx.staged[key])
+	}
+	return changes
+}
+
+// stagingTx stages changes for stores without transaction support
+type stagingTx struct {
+	store  ConfigStore
+	staged map[string]change
+	order  []string
+}
+
+func (tx *stagingTx) Get(ctx context.Context, key string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(key))
+	if ch, ok := tx.staged[normalized]; ok {
+		if ch.deleted {
+			return "", &ErrKeyNotFound{key: normalized}
+		}
+		return ch.value, nil
+	}
+	return tx.store.Get(ctx, key)
+}
+
+func (tx *stagingTx) Has(ctx context.Context, key string) bool {
+	normalized := strings.ToUpper(strings.TrimSpace(key))
+	for stagedKey, ch := range tx.staged {
+		if !ch.deleted && matchesKey(stagedKey, normalized) {
+			return true
+		}
+	}
+	if ch, ok := tx.staged[normalized]; ok && ch.deleted {
+		return len(tx.store.GetAll(ctx, normalized)) > 1
+	}
+	return tx.store.Has(ctx, key)
+}
+
+func (tx *stagingTx) Set(ctx context.Context, key string, value string) error {
+	return tx.stage(change{key: key, value: value})
+}
+
+func (tx *stagingTx) Delete(ctx context.Context, key string) error {
+	return tx.stage(change{key: key, deleted: true})
+}
+
+func (tx *stagingTx) stage(ch change) error {
+	ch.key = strings.ToUpper(strings.TrimSpace(ch.key))
+	if err := IsValidKey(ch.key); err != nil { // check key is valid
+		return err
+	}
+	if _, ok := tx.staged[ch.key]; !ok {
+		tx.order = append(tx.order, ch.key)
+	}
+	tx.staged[ch.key] = ch
+	return nil
+}
+
+// updateStore runs fn against a staging transaction and applies the staged changes one by one.
+// If a change fails, the changes applied so far are reverted.
+func updateStore(ctx context.Context, store ConfigStore, fn func(tx Tx) error) error {
+	tx := &stagingTx{store: store, staged: make(map[string]change)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	applied := []change{}
+	for _, key := range tx.order {
+		ch := tx.staged[key]
+		previous, exists := store.GetAll(ctx, key)[""]
+		if err := applyChange(ctx, store, ch); err != nil {
+			revertChanges(ctx, store, applied)
+			return err
+		}
+		applied = append(applied, change{key: key, value: previous, deleted: !exists})
+	}
+	return nil
+}
+
+func applyChange(ctx context.Context, store ConfigStore, ch change) error {
+	if !ch.deleted {
+		return store.Set(ctx, ch.key, ch.value, true)
+	}
+	var notFound *ErrKeyNotFound
+	if err := store.Delete(ctx, ch.key); err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+	return nil
+}
+
+// revertChanges restores the previous states in reverse order, errors are ignored as the
+// original error is reported
+func revertChanges(ctx context.Context, store ConfigStore, previous []change) {
+	for i := len(previous) - 1; i >= 0; i-- {
+		_ = applyChange(context.WithoutCancel(ctx), store, previous[i])
+	}
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingSetStore fails every Set of failKey
+type failingSetStore struct {
+	ConfigStore
+	failKey string
+}
+
+func (s failingSetStore) Set(ctx context.Context, key string, value string, force bool) error {
+	if strings.EqualFold(key, s.failKey) {
+		return errors.New("set failed")
+	}
+	return s.ConfigStore.Set(ctx, key, value, force)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1", "b": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := config.Version()
+	err = config.Update(ctx, func(tx Tx) error {
+		if err := tx.Set(ctx, "a", "changed"); err != nil {
+			return err
+		}
+		if err := tx.Delete(ctx, "b"); err != nil {
+			return err
+		}
+		if err := tx.Set(ctx, "c", "3"); err != nil {
+			return err
+		}
+		// reads see the staged changes
+		if value, err := tx.Get(ctx, "A"); err != nil || value != "changed" {
+			t.Errorf("Expected staged value, got '%s' (%v)", value, err)
+		}
+		if tx.Has(ctx, "B") {
+			t.Error("Deleted key is visible in transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "changed", "C": "3"}, true); err != nil {
+		t.Error(err)
+	}
+	if config.Has(ctx, "B") {
+		t.Error("Key was not deleted")
+	}
+	if config.Version() != version+1 {
+		t.Errorf("Expected a single version for the update, got %d -> %d", version, config.Version())
+	}
+}
+
+func TestUpdateRollbackOnError(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := config.Version()
+	txErr := errors.New("abort")
+	err = config.Update(ctx, func(tx Tx) error {
+		if err := tx.Set(ctx, "a", "changed"); err != nil {
+			return err
+		}
+		if err := tx.Set(ctx, "b", "2"); err != nil {
+			return err
+		}
+		return txErr
+	})
+	if !errors.Is(err, txErr) {
+		t.Errorf("Expected transaction error, got %v", err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "1"}, true); err != nil {
+		t.Error(err)
+	}
+	if config.Has(ctx, "B") || config.Version() != version {
+		t.Error("Failed transaction modified the store")
+	}
+}
+
+func TestUpdateConsistentReads(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "0", "b": "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			value := strconv.Itoa(i)
+			_ = config.Update(ctx, func(tx Tx) error {
+				_ = tx.Set(ctx, "a", value)
+				return tx.Set(ctx, "b", value)
+			})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		snapshot := config.Snapshot()
+		a, _ := snapshot.Get(ctx, "a")
+		b, _ := snapshot.Get(ctx, "b")
+		if a != b {
+			t.Fatalf("Observed partially applied update: a=%s b=%s", a, b)
+		}
+	}
+	wg.Wait()
+}
+
+func TestUpdateReadsConfig(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1", "b": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := 0
+	err = config.Update(ctx, func(tx Tx) error {
+		runs++
+		// reading the config in a transaction does not block
+		a, err := config.Get(ctx, "A")
+		if err != nil {
+			return err
+		}
+		if runs == 1 {
+			// a concurrent write runs the transaction again on the new state
+			if err := config.Set(ctx, "A", "2", true); err != nil {
+				return err
+			}
+		}
+		return tx.Set(ctx, "B", a)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Errorf("Expected the transaction to run again, got %d runs", runs)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "2", "B": "2"}, true); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := WithInitialValues(ctx, map[string]interface{}{"a": nil}); !errors.Is(err, ErrValueInvalid) {
+		t.Errorf("Expected nil value to be rejected, got %v", err)
+	}
+}
+
+func TestMergeAtomic(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	merger, err := WithInitialValues(ctx, map[string]interface{}{"a": "other", "b": "2", "c": "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Merge(ctx, merger, false); !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected key in store error, got %v", err)
+	}
+	if config.Has(ctx, "B") || config.Has(ctx, "C") {
+		t.Error("Failed merge was partially applied")
+	}
+	if err := config.MergeIn(ctx, "nested", merger, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.MergeIn(ctx, "nested", merger, false); !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected key in store error, got %v", err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "1", "NESTED/A": "other", "NESTED/B": "2", "NESTED/C": "3"}, true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateUnsupportedStore(t *testing.T) {
+	ctx := context.TODO()
+	base, err := WithInitialValues(ctx, map[string]interface{}{"a": "1", "b": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{ConfigStore: failingSetStore{ConfigStore: plainStore{base.ConfigStore}, failKey: "FAIL"}}
+	err = config.Update(ctx, func(tx Tx) error {
+		if err := tx.Set(ctx, "a", "changed"); err != nil {
+			return err
+		}
+		if err := tx.Delete(ctx, "b"); err != nil {
+			return err
+		}
+		if value, err := tx.Get(ctx, "a"); err != nil || value != "changed" {
+			t.Errorf("Expected staged value, got '%s' (%v)", value, err)
+		}
+		return tx.Set(ctx, "fail", "x")
+	})
+	if err == nil {
+		t.Fatal("Expected update to fail")
+	}
+	// applied changes are reverted
+	if err := config.CompareMap(ctx, map[string]string{"A": "1", "B": "2"}, true); err != nil {
+		t.Error(err)
+	}
+
+	err = config.Update(ctx, func(tx Tx) error {
+		return tx.Set(ctx, "c", "3")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "C"); err != nil || value != "3" {
+		t.Errorf("Expected '3', got '%s' (%v)", value, err)
+	}
+}
+
+func TestUpdateInvalidKey(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "bad key!", "a//b"} {
+		err := config.Update(ctx, func(tx Tx) error {
+			return tx.Set(ctx, key, "x")
+		})
+		var keyErr *ErrKeyValueInvalid
+		if !errors.As(err, &keyErr) {
+			t.Errorf("Expected invalid key error for '%s', got %v", key, err)
+		}
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "1"}, true); err != nil {
+		t.Error(err)
+	}
+
+	store := config.ConfigStore.(*ConfigStoreImpl)
+	err = config.Update(ctx, func(tx Tx) error {
+		return tx.Set(ctx, "b", "2")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only snapshots and running transactions force a copy on the next write
+	if store.shared || store.readers != 0 {
+		t.Errorf("Store is still shared after the update: shared=%t readers=%d", store.shared, store.readers)
+	}
+}
@@ -0,0 +1,343 @@
+package config
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// TypedStore is implemented by stores that keep the original value next to its string form
+type TypedStore interface {
+	ConfigStore
+	// SetTyped stores value and its string form, see Set for force
+	SetTyped(ctx context.Context, key string, value any, force bool) error
+	// GetTyped returns the value as it was set, keys set as string return the string
+	GetTyped(ctx context.Context, key string) (any, error)
+}
+
+// typedTx is implemented by transactions of typed stores
+type typedTx interface {
+	SetTyped(ctx context.Context, key string, value any) error
+}
+
+// Get returns the value of key as T.
+// Typed values are returned without parsing, other values are converted to T.
+func Get[T any](ctx context.Context, store ConfigStore, key string) (T, error) {
+	var result T
+	raw, err := getTyped(ctx, store, key)
+	if err != nil {
+		return result, err
+	}
+	if value, ok := raw.(T); ok {
+		return value, nil
+	}
+	if err := convertValue(key, raw, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Set stores value for key, typed stores keep the value so Get does not need to parse it
+func Set[T any](ctx context.Context, store ConfigStore, key string, value T, force bool) error {
+	if typed, ok := store.(TypedStore); ok {
+		return typed.SetTyped(ctx, key, value, force)
+	}
+	formatted, err := formatValue(key, value)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, key, formatted, force)
+}
+
+// GetSlice returns the list stored below key as KEY/0, KEY/1, ... with each item converted to T
+func GetSlice[T any](ctx context.Context, store ConfigStore, key string) ([]T, error) {
+	values := []T{}
+	for i := 0; ; i++ {
+		itemKey := keytree.Join(key, strconv.Itoa(i))
+		if !store.Has(ctx, itemKey) {
+			break
+		}
+		value, err := Get[T](ctx, store, itemKey)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, &ErrKeyNotFound{key: key}
+	}
+	return values, nil
+}
+
+func getTyped(ctx context.Context, store ConfigStore, key string) (any, error) {
+	if typed, ok := store.(TypedStore); ok {
+		return typed.GetTyped(ctx, key)
+	}
+	return store.Get(ctx, key)
+}
+
+func (c *ConfigStoreImpl) SetTyped(ctx context.Context, key string, value any, force bool) error {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return err
+	}
+	ch, err := typedChange(key, value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.store[key]; ok && !force {
+		return &ErrKeyInStore{key: key}
+	}
+	c.commit([]change{ch})
+	return nil
+}
+
+func (c *ConfigStoreImpl) GetTyped(ctx context.Context, key string) (any, error) {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return nil, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return storeMap(c.store).getTyped(ctx, c.typed, key)
+}
+
+func (s *snapshotStore) SetTyped(ctx context.Context, key string, value any, force bool) error {
+	return &ErrSnapshotReadOnly{key: key}
+}
+
+func (s *snapshotStore) GetTyped(ctx context.Context, key string) (any, error) {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return nil, err
+	}
+	return s.store.getTyped(ctx, s.typed, key)
+}
+
+func (p *prefixStore) SetTyped(ctx context.Context, key string, value any, force bool) error {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return Set(ctx, p.parent, fullKey, value, force)
+}
+
+func (p *prefixStore) GetTyped(ctx context.Context, key string) (any, error) {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return getTyped(ctx, p.parent, fullKey)
+}
+
+func (tx *memoryTx) SetTyped(ctx context.Context, key string, value any) error {
+	ch, err := typedChange(key, value)
+	if err != nil {
+		return err
+	}
+	return tx.stage(ch)
+}
+
+func (t *prefixTx) SetTyped(ctx context.Context, key string, value any) error {
+	fullKey, err := t.store.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return setTx(ctx, t.tx, fullKey, value)
+}
+
+// setTx stages a typed value if the transaction supports it, otherwise the string form
+func setTx(ctx context.Context, tx Tx, key string, value any) error {
+	if typed, ok := tx.(typedTx); ok {
+		return typed.SetTyped(ctx, key, value)
+	}
+	formatted, err := formatValue(key, value)
+	if err != nil {
+		return err
+	}
+	return tx.Set(ctx, key, formatted)
+}
+
+// typedChange creates the change for a typed value, strings are stored without a typed value
+func typedChange(key string, value any) (change, error) {
+	formatted, err := formatValue(key, value)
+	if err != nil {
+		return change{}, err
+	}
+	if _, ok := value.(string); ok {
+		return change{key: key, value: formatted}, nil
+	}
+	return change{key: key, value: formatted, typed: value}, nil
+}
+
+func (m storeMap) getTyped(ctx context.Context, typed map[string]any, key string) (any, error) {
+	value, err := m.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if typedValue, ok := typed[key]; ok {
+		return typedValue, nil
+	}
+	return value, nil
+}
+
+// typedEqual reports if two typed values are the same, values that can't be compared are never equal
+func typedEqual(a any, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	typeA := reflect.TypeOf(a)
+	if typeA != reflect.TypeOf(b) || !typeA.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// formatValue returns the string form of a value
+func formatValue(key string, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return "", &ErrKeyValueInvalid{key: key, value: value, nested: err}
+		}
+		return string(text), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits()), nil
+	default:
+		return "", &ErrKeyValueInvalid{key: key, value: value}
+	}
+}
+
+// convertValue stores raw in target, numbers are converted directly if no precision is lost,
+// everything else is decoded from its string form with a registered decoder or the basic parsers
+func convertValue(key string, raw any, target any) error {
+	rv := reflect.ValueOf(target).Elem()
+	if converted, ok := convertNumber(raw, rv.Type()); ok {
+		rv.Set(converted)
+		return nil
+	}
+	text, err := formatValue(key, raw)
+	if err != nil {
+		return err
+	}
+	if decode, ok := lookupDecoder(rv.Type()); ok {
+		value, err := decode(strings.TrimSpace(text))
+		if err != nil {
+			return &ErrFieldType{key: key, typeName: rv.Type().String(), nested: err}
+		}
+		rv.Set(reflect.ValueOf(value))
+		return nil
+	}
+	if err := decodeString(text, target); err != nil {
+		return &ErrFieldType{key: key, typeName: rv.Type().String(), nested: err}
+	}
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return reflect.Int <= kind && kind <= reflect.Float64
+}
+
+// durationType is not converted from plain numbers, a bare 30 has no unit
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convertNumber converts between number types, it fails if the conversion is lossy.
+// Numbers are not converted to durations, they are parsed like the string form and rejected without a unit.
+func convertNumber(raw any, target reflect.Type) (reflect.Value, bool) {
+	value := reflect.ValueOf(raw)
+	if raw == nil || !isNumberKind(value.Kind()) || !isNumberKind(target.Kind()) || target == durationType {
+		return reflect.Value{}, false
+	}
+	// converting back does not catch sign changes, e.g. -1 to uint and back
+	if isIntKind(value.Kind()) && isUintKind(target.Kind()) && value.Int() < 0 {
+		return reflect.Value{}, false
+	}
+	if isUintKind(value.Kind()) && isIntKind(target.Kind()) && value.Uint() > math.MaxInt64>>(64-target.Bits()) {
+		return reflect.Value{}, false
+	}
+	converted := value.Convert(target)
+	if !converted.Convert(value.Type()).Equal(value) {
+		return reflect.Value{}, false
+	}
+	return converted, true
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return reflect.Int <= kind && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return reflect.Uint <= kind && kind <= reflect.Uintptr
+}
+
+// decodeString parses text into target, a pointer to a text unmarshaler or a basic type
+func decodeString(text string, target any) error {
+	if unmarshaler, ok := target.(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+	rv := reflect.ValueOf(target).Elem()
+	text = strings.TrimSpace(text)
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(text)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(text, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(text, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(value)
+	default:
+		return ErrTypeMismatch
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetTyped(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"int":   42,
+		"bool":  true,
+		"float": 1.5,
+		"text":  "7",
+		"dur":   time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the string form is kept next to the typed value
+	if value, err := config.Get(ctx, "INT"); err != nil || value != "42" {
+		t.Errorf("Expected '42', got '%s' (%v)", value, err)
+	}
+	if value, err := config.Get(ctx, "DUR"); err != nil || value != "1s" {
+		t.Errorf("Expected '1s', got '%s' (%v)", value, err)
+	}
+	if raw, err := config.GetTyped(ctx, "INT"); err != nil || raw != 42 {
+		t.Errorf("Expected typed 42, got %v (%v)", raw, err)
+	}
+	if raw, err := config.GetTyped(ctx, "TEXT"); err != nil || raw != "7" {
+		t.Errorf("Expected string '7', got %v (%v)", raw, err)
+	}
+
+	if value, err := Get[int](ctx, config, "INT"); err != nil || value != 42 {
+		t.Errorf("Expected 42, got %d (%v)", value, err)
+	}
+	if value, err := Get[int64](ctx, config, "INT"); err != nil || value != 42 {
+		t.Errorf("Expected converted 42, got %d (%v)", value, err)
+	}
+	if value, err := Get[bool](ctx, config, "BOOL"); err != nil || !value {
+		t.Errorf("Expected true, got %t (%v)", value, err)
+	}
+	if value, err := Get[int](ctx, config, "TEXT"); err != nil || value != 7 {
+		t.Errorf("Expected parsed 7, got %d (%v)", value, err)
+	}
+	if value, err := Get[string](ctx, config, "FLOAT"); err != nil || value != "1.5" {
+		t.Errorf("Expected '1.5', got '%s' (%v)", value, err)
+	}
+	// lossy conversions are not done directly
+	if _, err := Get[int](ctx, config, "FLOAT"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+	if _, err := Get[bool](ctx, config, "TEXT"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+	// conversions changing the sign are rejected like the string form
+	signed, err := WithInitialValues(ctx, map[string]interface{}{"neg": -1, "small": int8(-1), "big": uint64(1 << 63), "byte": uint8(255)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get[uint](ctx, signed, "NEG"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected -1 not to convert to uint, got %d (%v)", value, err)
+	}
+	if value, err := Get[uint8](ctx, signed, "SMALL"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected -1 not to convert to uint8, got %d (%v)", value, err)
+	}
+	if value, err := Get[int64](ctx, signed, "BIG"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected 1<<63 not to convert to int64, got %d (%v)", value, err)
+	}
+	if value, err := Get[int8](ctx, signed, "BYTE"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected 255 not to convert to int8, got %d (%v)", value, err)
+	}
+	if value, err := Get[int16](ctx, signed, "BYTE"); err != nil || value != 255 {
+		t.Errorf("Expected 255, got %d (%v)", value, err)
+	}
+	// numbers have no unit, they are not read as nanoseconds
+	if value, err := Get[time.Duration](ctx, config, "INT"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected invalid duration, got %v (%v)", value, err)
+	}
+	if value, err := Get[time.Duration](ctx, config, "DUR"); err != nil || value != time.Second {
+		t.Errorf("Expected 1s, got %v (%v)", value, err)
+	}
+	if _, err := Get[int](ctx, config, "MISSING"); !errors.Is(err, ErrConfigKey) {
+		t.Errorf("Expected key not found, got %v", err)
+	}
+}
+
+func TestSetTyped(t *testing.T) {
+	ctx := context.TODO()
+	for name, store := range storeImplementations(t) {
+		if err := Set(ctx, store, "PORT", uint16(8080), false); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := Set(ctx, store, "PORT", uint16(9090), false); !errors.Is(err, ErrConfigKey) {
+			t.Errorf("%s: expected key in store error, got %v", name, err)
+		}
+		if value, err := Get[uint16](ctx, store, "PORT"); err != nil || value != 8080 {
+			t.Errorf("%s: expected 8080, got %d (%v)", name, value, err)
+		}
+		if value, err := store.Get(ctx, "PORT"); err != nil || value != "8080" {
+			t.Errorf("%s: expected '8080', got '%s' (%v)", name, value, err)
+		}
+		// a string set replaces the typed value
+		if err := store.Set(ctx, "PORT", "80", true); err != nil {
+			t.Fatal(err)
+		}
+		if value, err := Get[uint16](ctx, store, "PORT"); err != nil || value != 80 {
+			t.Errorf("%s: expected 80, got %d (%v)", name, value, err)
+		}
+	}
+}
+
+func TestTypedHistory(t *testing.T) {
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(ctx, config, "A", 1, true); err != nil {
+		t.Fatal(err)
+	}
+	version := config.Version()
+	snapshot := config.Snapshot()
+	if err := Set(ctx, config, "A", int64(1), true); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version() != version+1 {
+		t.Error("Changing the type of a value is not versioned")
+	}
+	if raw, err := snapshot.GetTyped(ctx, "A"); err != nil || raw != 1 {
+		t.Errorf("Expected snapshot to keep typed 1, got %v (%v)", raw, err)
+	}
+	if err := config.Rollback(ctx, version); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := config.GetTyped(ctx, "A"); err != nil || raw != 1 {
+		t.Errorf("Expected rollback to restore typed 1, got %v (%v)", raw, err)
+	}
+}
+
+func TestGetSlice(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"ports": []interface{}{8080, "8081"},
+		"users": []interface{}{map[string]interface{}{"name": "admin", "role": "root"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports, err := GetSlice[int](ctx, config, "ports")
+	if err != nil || len(ports) != 2 || ports[0] != 8080 || ports[1] != 8081 {
+		t.Errorf("Expected [8080 8081], got %v (%v)", ports, err)
+	}
+	var notFound *ErrKeyNotFound
+	if _, err := GetSlice[int](ctx, config, "missing"); !errors.As(err, &notFound) {
+		t.Errorf("Expected key not found, got %v", err)
+	}
+	if _, err := GetSlice[string](ctx, config, "users"); err == nil {
+		t.Error("Expected error for nested items")
+	}
+}
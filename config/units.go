@@ -0,0 +1,217 @@
+package config
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ByteSize is a size in bytes, parsed from values like 100MB (SI) or 1.5GiB (IEC)
+type ByteSize uint64
+
+const (
+	BYTE ByteSize = 1
+
+	KILOBYTE ByteSize = 1000 * BYTE
+	MEGABYTE ByteSize = 1000 * KILOBYTE
+	GIGABYTE ByteSize = 1000 * MEGABYTE
+	TERABYTE ByteSize = 1000 * GIGABYTE
+	PETABYTE ByteSize = 1000 * TERABYTE
+	EXABYTE  ByteSize = 1000 * PETABYTE
+
+	KIBIBYTE ByteSize = 1024 * BYTE
+	MEBIBYTE ByteSize = 1024 * KIBIBYTE
+	GIBIBYTE ByteSize = 1024 * MEBIBYTE
+	TEBIBYTE ByteSize = 1024 * GIBIBYTE
+	PEBIBYTE ByteSize = 1024 * TEBIBYTE
+	EXBIBYTE ByteSize = 1024 * PEBIBYTE
+
+	DAY  = 24 * time.Hour
+	WEEK = 7 * DAY
+)
+
+// byteUnits maps lower case unit names to their size, a unit without 'i' is an SI unit
+var byteUnits = map[string]ByteSize{
+	"":    BYTE,
+	"b":   BYTE,
+	"k":   KILOBYTE,
+	"kb":  KILOBYTE,
+	"m":   MEGABYTE,
+	"mb":  MEGABYTE,
+	"g":   GIGABYTE,
+	"gb":  GIGABYTE,
+	"t":   TERABYTE,
+	"tb":  TERABYTE,
+	"p":   PETABYTE,
+	"pb":  PETABYTE,
+	"e":   EXABYTE,
+	"eb":  EXABYTE,
+	"ki":  KIBIBYTE,
+	"kib": KIBIBYTE,
+	"mi":  MEBIBYTE,
+	"mib": MEBIBYTE,
+	"gi":  GIBIBYTE,
+	"gib": GIBIBYTE,
+	"ti":  TEBIBYTE,
+	"tib": TEBIBYTE,
+	"pi":  PEBIBYTE,
+	"pib": PEBIBYTE,
+	"ei":  EXBIBYTE,
+	"eib": EXBIBYTE,
+}
+
+// byteSizeNames are used for formatting, larger units first
+var byteSizeNames = []struct {
+	name string
+	size ByteSize
+}{
+	{"EiB", EXBIBYTE}, {"EB", EXABYTE},
+	{"PiB", PEBIBYTE}, {"PB", PETABYTE},
+	{"TiB", TEBIBYTE}, {"TB", TERABYTE},
+	{"GiB", GIBIBYTE}, {"GB", GIGABYTE},
+	{"MiB", MEBIBYTE}, {"MB", MEGABYTE},
+	{"KiB", KIBIBYTE}, {"KB", KILOBYTE},
+}
+
+// ParseByteSize parses sizes like 512, 100MB or 1.5GiB, units are case-insensitive.
+// Units without 'i' are SI units (powers of 1000), units with 'i' are IEC units (powers of 1024).
+func ParseByteSize(raw string) (ByteSize, error) {
+	number, unit := splitUnit(strings.TrimSpace(raw))
+	size, ok := byteUnits[strings.ToLower(unit)]
+	if !ok || number == "" {
+		return 0, &ErrSizeInvalid{value: raw}
+	}
+	if value, err := strconv.ParseUint(number, 10, 64); err == nil {
+		if value > math.MaxUint64/uint64(size) {
+			return 0, &ErrSizeInvalid{value: raw, reason: "out of range"}
+		}
+		return ByteSize(value) * size, nil
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 || math.IsNaN(value) {
+		return 0, &ErrSizeInvalid{value: raw}
+	}
+	bytes := value * float64(size)
+	if bytes >= math.MaxUint64 {
+		return 0, &ErrSizeInvalid{value: raw, reason: "out of range"}
+	}
+	return ByteSize(math.Round(bytes)), nil
+}
+
+// String formats the size with the largest unit that represents it exactly
+func (s ByteSize) String() string {
+	for _, unit := range byteSizeNames {
+		if s >= unit.size && s%unit.size == 0 {
+			return strconv.FormatUint(uint64(s/unit.size), 10) + unit.name
+		}
+	}
+	return strconv.FormatUint(uint64(s), 10) + "B"
+}
+
+func (s *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// Percent is a ratio, 75% is stored as 0.75
+type Percent float64
+
+// ParsePercent parses values like 75% or 12.5 %, values without a percent sign are taken as ratio
+func ParsePercent(raw string) (Percent, error) {
+	trimmed := strings.TrimSpace(raw)
+	number, isPercent := strings.CutSuffix(trimmed, "%")
+	value, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, &ErrPercentInvalid{value: raw}
+	}
+	if isPercent {
+		value /= 100
+	}
+	return Percent(value), nil
+}
+
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p)*100, 'f', -1, 64) + "%"
+}
+
+func (p *Percent) UnmarshalText(text []byte) error {
+	percent, err := ParsePercent(string(text))
+	if err != nil {
+		return err
+	}
+	*p = percent
+	return nil
+}
+
+// ParseDuration parses durations like time.ParseDuration, additionally accepting days (d)
+// and weeks (w), e.g. 1d12h or 2w. A day is always 24 hours.
+func ParseDuration(raw string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(raw)
+	rest := trimmed
+	negative := strings.HasPrefix(rest, "-")
+	rest = strings.TrimLeft(rest, "+-")
+	if rest == "" {
+		return 0, &ErrDurationInvalid{value: raw}
+	}
+	var long float64
+	standard := ""
+	for rest != "" {
+		end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+		if end <= 0 {
+			return 0, &ErrDurationInvalid{value: raw}
+		}
+		number := rest[:end]
+		rest = rest[end:]
+		unitEnd := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+		if unitEnd < 0 {
+			unitEnd = len(rest)
+		}
+		unit := rest[:unitEnd]
+		rest = rest[unitEnd:]
+		switch unit {
+		case "d", "w":
+			value, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, &ErrDurationInvalid{value: raw}
+			}
+			if unit == "w" {
+				long += value * float64(WEEK)
+			} else {
+				long += value * float64(DAY)
+			}
+		default:
+			standard += number + unit
+		}
+	}
+	var duration time.Duration
+	if standard != "" {
+		parsed, err := time.ParseDuration(standard)
+		if err != nil {
+			return 0, &ErrDurationInvalid{value: raw, reason: err.Error()}
+		}
+		duration = parsed
+	}
+	if long+float64(duration) > math.MaxInt64 {
+		return 0, &ErrDurationInvalid{value: raw, reason: "out of range"}
+	}
+	duration += time.Duration(long)
+	if negative {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+// splitUnit splits a value like 1.5GiB into its number and unit
+func splitUnit(raw string) (string, string) {
+	end := strings.IndexFunc(raw, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	if end < 0 {
+		return raw, ""
+	}
+	return raw[:end], strings.TrimSpace(raw[end:])
+}
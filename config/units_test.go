@@ -0,0 +1,106 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for raw, expected := range map[string]ByteSize{
+		"512":     512,
+		"100MB":   100 * MEGABYTE,
+		"100 mb":  100 * MEGABYTE,
+		"1KiB":    1024,
+		"1.5GiB":  GIBIBYTE + GIBIBYTE/2,
+		"2k":      2000,
+		"1.5 KB":  1500,
+		" 3 TiB ": 3 * TEBIBYTE,
+	} {
+		if size, err := ParseByteSize(raw); err != nil || size != expected {
+			t.Errorf("Expected %d for '%s', got %d (%v)", expected, raw, size, err)
+		}
+	}
+	for _, raw := range []string{"", "MB", "12XB", "-1MB", "1.2.3KB", "20EiB"} {
+		if _, err := ParseByteSize(raw); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("Expected type mismatch for '%s', got %v", raw, err)
+		}
+	}
+	for size, expected := range map[ByteSize]string{
+		100 * MEGABYTE: "100MB",
+		3 * MEBIBYTE:   "3MiB",
+		1500:           "1500B",
+		0:              "0B",
+	} {
+		if size.String() != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, size.String())
+		}
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	for raw, expected := range map[string]Percent{
+		"75%":    0.75,
+		"12.5 %": 0.125,
+		"0.3":    0.3,
+		"150%":   1.5,
+	} {
+		if percent, err := ParsePercent(raw); err != nil || percent != expected {
+			t.Errorf("Expected %f for '%s', got %f (%v)", expected, raw, percent, err)
+		}
+	}
+	for _, raw := range []string{"", "%", "abc%", "NaN"} {
+		if _, err := ParsePercent(raw); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("Expected type mismatch for '%s', got %v", raw, err)
+		}
+	}
+	if Percent(0.75).String() != "75%" {
+		t.Errorf("Expected '75%%', got '%s'", Percent(0.75).String())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for raw, expected := range map[string]time.Duration{
+		"90s":      90 * time.Second,
+		"1d12h":    36 * time.Hour,
+		"2w":       14 * DAY,
+		"1w2d3h4m": WEEK + 2*DAY + 3*time.Hour + 4*time.Minute,
+		"0.5d":     12 * time.Hour,
+		"-1d":      -DAY,
+		"1h1d":     25 * time.Hour,
+	} {
+		if duration, err := ParseDuration(raw); err != nil || duration != expected {
+			t.Errorf("Expected %s for '%s', got %s (%v)", expected, raw, duration, err)
+		}
+	}
+	for _, raw := range []string{"", "d", "1x", "10", "1.2.3d", "99999999w"} {
+		if _, err := ParseDuration(raw); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("Expected type mismatch for '%s', got %v", raw, err)
+		}
+	}
+}
+
+func TestGetUnits(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"maxsize": "100MB",
+		"ratio":   "75%",
+		"timeout": "1d12h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := Get[ByteSize](ctx, config, "MAXSIZE"); err != nil || value != 100*MEGABYTE {
+		t.Errorf("Expected 100MB, got %s (%v)", value, err)
+	}
+	if value, err := Get[Percent](ctx, config, "RATIO"); err != nil || value != 0.75 {
+		t.Errorf("Expected 75%%, got %s (%v)", value, err)
+	}
+	if value, err := Get[time.Duration](ctx, config, "TIMEOUT"); err != nil || value != 36*time.Hour {
+		t.Errorf("Expected 36h, got %s (%v)", value, err)
+	}
+	if _, err := Get[ByteSize](ctx, config, "RATIO"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Expected type mismatch, got %v", err)
+	}
+}
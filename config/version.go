@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"strings"
+)
+
+const DEFAULT_HISTORY_LIMIT = 100
+
+// VersionedStore is implemented by stores that keep a history of their mutations
+type VersionedStore interface {
+	ConfigStore
+	// Version returns the current version, every mutation creates a new version
+	Version() uint64
+	// Snapshot returns an immutable view of the current version
+	Snapshot() ConfigStore
+	// Rollback restores the state of a previous version
+	Rollback(ctx context.Context, version uint64) error
+}
+
+// change describes a single key update.
+// History entries hold the state of the key before the update of their version.
+type change struct {
+	version uint64
+	key     string
+	value   string
+	// typed is the original value if the key was set with SetTyped
+	typed   any
+	deleted bool
+}
+
+// commit applies changes as a single new version, the write lock has to be held.
+// Changes that do not modify the store are dropped, if none remain no version is created.
+func (c *ConfigStoreImpl) commit(changes []change) {
+	applied := false
+	for _, ch := range changes {
+		old, existed := c.store[ch.key]
+		oldTyped := c.typed[ch.key]
+		if ch.deleted && !existed || !ch.deleted && existed && old == ch.value && typedEqual(oldTyped, ch.typed) {
+			continue
+		}
+		if !applied {
+			c.version++
+			c.unshare()
+			applied = true
+		}
+		c.history = append(c.history, change{version: c.version, key: ch.key, value: old, typed: oldTyped, deleted: !existed})
+		if ch.deleted {
+			delete(c.store, ch.key)
+		} else {
+			c.store[ch.key] = ch.value
+		}
+		if ch.deleted || ch.typed == nil {
+			delete(c.typed, ch.key)
+		} else {
+			c.typed[ch.key] = ch.typed
+		}
+	}
+	if applied {
+		c.trimHistory()
+	}
+}
+
+// unshare copies the maps if they are referenced by a snapshot or transaction, so those stay unchanged
+func (c *ConfigStoreImpl) unshare() {
+	shared := c.shared || c.readers > 0
+	if c.store == nil {
+		c.store = make(map[string]string)
+	} else if shared {
+		buffer := make(map[string]string, len(c.store))
+		for key, value := range c.store {
+			buffer[key] = value
+		}
+		c.store = buffer
+	}
+	if c.typed == nil {
+		c.typed = make(map[string]any)
+	} else if shared {
+		buffer := make(map[string]any, len(c.typed))
+		for key, value := range c.typed {
+			buffer[key] = value
+		}
+		c.typed = buffer
+	}
+	// running transactions keep reading the old maps
+	c.shared = false
+	c.readers = 0
+}
+
+func (c *ConfigStoreImpl) trimHistory() {
+	limit := c.historyLimit
+	if limit <= 0 {
+		limit = DEFAULT_HISTORY_LIMIT
+	}
+	if c.version <= uint64(limit) || c.version-uint64(limit) <= c.oldest {
+		return
+	}
+	c.oldest = c.version - uint64(limit)
+	index := 0
+	for index < len(c.history) && c.history[index].version <= c.oldest {
+		index++
+	}
+	c.history = c.history[index:]
+}
+
+func (c *ConfigStoreImpl) Version() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// SetHistoryLimit sets the number of versions that can be rolled back, defaults to DEFAULT_HISTORY_LIMIT
+func (c *ConfigStoreImpl) SetHistoryLimit(limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.historyLimit = limit
+	c.trimHistory()
+}
+
+// Snapshot returns an immutable view of the current version.
+// Taking a snapshot is cheap, the data is only copied on the next write to the store.
+func (c *ConfigStoreImpl) Snapshot() ConfigStore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		c.store = make(map[string]string)
+	}
+	c.shared = true
+	return &snapshotStore{store: c.store, typed: c.typed, version: c.version}
+}
+
+// Rollback restores the state of a previous version.
+// The rollback is stored as a new version, so it can be rolled back itself.
+func (c *ConfigStoreImpl) Rollback(ctx context.Context, version uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version > c.version || version < c.oldest {
+		return &ErrVersionNotFound{version: version}
+	}
+	restore := make(map[string]change)
+	// walk back in time, so the oldest state of each key after the version wins
+	for i := len(c.history) - 1; i >= 0 && c.history[i].version > version; i-- {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := c.history[i]
+		restore[entry.key] = change{key: entry.key, value: entry.value, typed: entry.typed, deleted: entry.deleted}
+	}
+	changes := make([]change, 0, len(restore))
+	for _, ch := range restore {
+		changes = append(changes, ch)
+	}
+	c.commit(changes)
+	return nil
+}
+
+// snapshotStore is an immutable store, it does not need any locking
+type snapshotStore struct {
+	store   storeMap
+	typed   map[string]any
+	version uint64
+}
+
+func (s *snapshotStore) Get(ctx context.Context, key string) (string, error) {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return "", err
+	}
+	return s.store.get(ctx, key)
+}
+
+func (s *snapshotStore) GetAll(ctx context.Context, key string) map[string]string {
+	return s.store.getAll(ctx, key)
+}
+
+func (s *snapshotStore) Set(ctx context.Context, key string, value string, force bool) error {
+	return &ErrSnapshotReadOnly{key: key}
+}
+
+func (s *snapshotStore) Delete(ctx context.Context, key string) error {
+	return &ErrSnapshotReadOnly{key: key}
+}
+
+func (s *snapshotStore) DeletePrefix(ctx context.Context, prefix string) error {
+	return &ErrSnapshotReadOnly{key: prefix}
+}
+
+func (s *snapshotStore) Has(ctx context.Context, key string) bool {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return false
+	}
+	return s.store.has(ctx, key)
+}
+
+func (s *snapshotStore) Keys(ctx context.Context) []string {
+	return s.store.keys(ctx)
+}
+
+func (s *snapshotStore) Version() uint64 {
+	return s.version
+}
+
+func (s *snapshotStore) Snapshot() ConfigStore {
+	return s
+}
+
+func (s *snapshotStore) Rollback(ctx context.Context, version uint64) error {
+	return &ErrSnapshotReadOnly{}
+}
+
+// Version returns the version of the underlying store, or 0 if it is not versioned
+func (c *Config) Version() uint64 {
+	if store, ok := c.ConfigStore.(VersionedStore); ok {
+		return store.Version()
+	}
+	return 0
+}
+
+// Snapshot returns an immutable copy of the config.
+// For versioned stores this is a cheap copy-on-write view, other stores are copied.
+func (c *Config) Snapshot() *Config {
+	var snapshot ConfigStore
+	if store, ok := c.ConfigStore.(VersionedStore); ok {
+		snapshot = store.Snapshot()
+	} else {
+		snapshot = &snapshotStore{store: collectValues(context.Background(), c.ConfigStore, "")}
+	}
+	return &Config{
+		loader:      &ConfigLoader{},
+		profile:     c.profile,
+		aliases:     c.aliases.clone(),
+		computed:    c.computed.clone(),
+		newStore:    c.newStore,
+		ConfigStore: snapshot,
+	}
+}
+
+// Rollback restores the config to a previous version, see VersionedStore
+func (c *Config) Rollback(ctx context.Context, version uint64) error {
+	if err := c.checkFrozen(""); err != nil {
+		return err
+	}
+	store, ok := c.ConfigStore.(VersionedStore)
+	if !ok {
+		return ErrVersioningUnsupported
+	}
+	return store.Rollback(ctx, version)
+}
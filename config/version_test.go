@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// plainStore hides all optional interfaces of the wrapped store
+type plainStore struct {
+	ConfigStore
+}
+
+func TestVersionBump(t *testing.T) {
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Version() != 0 {
+		t.Errorf("Expected version 0, got %d", config.Version())
+	}
+	if err := config.Set(ctx, "A", "1", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "B", "2", true); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version() != 2 {
+		t.Errorf("Expected version 2, got %d", config.Version())
+	}
+	// setting the same value is not a mutation
+	if err := config.Set(ctx, "A", "1", true); err != nil {
+		t.Fatal(err)
+	}
+	if config.Version() != 2 {
+		t.Errorf("Expected version 2 after no-op, got %d", config.Version())
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"a": "1",
+		"b": "2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := config.Snapshot()
+	if snapshot.Version() != config.Version() {
+		t.Errorf("Expected snapshot version %d, got %d", config.Version(), snapshot.Version())
+	}
+	if err := config.Set(ctx, "A", "changed", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, "C", "3", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.CompareMap(ctx, map[string]string{"A": "1", "B": "2"}, true); err != nil {
+		t.Error(err)
+	}
+	if snapshot.Has(ctx, "C") {
+		t.Error("Snapshot sees later writes")
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "changed", "B": "2", "C": "3"}, true); err != nil {
+		t.Error(err)
+	}
+
+	err = snapshot.Set(ctx, "A", "other", true)
+	if !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+	if err := snapshot.Delete(ctx, "A"); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+	if err := snapshot.DeletePrefix(ctx, "A"); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+	if err := snapshot.Rollback(ctx, 0); !errors.Is(err, ErrConfigReadOnly) {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"1", "2", "3"} {
+		if err := config.Set(ctx, "A", value, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.Set(ctx, "B", "added", true); err != nil {
+		t.Fatal(err)
+	}
+	// version 1: A=1
+	if err := config.Rollback(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "1"}, true); err != nil {
+		t.Error(err)
+	}
+	if config.Has(ctx, "B") {
+		t.Error("Key added after version is still present")
+	}
+	if config.Version() != 5 {
+		t.Errorf("Expected rollback to create version 5, got %d", config.Version())
+	}
+
+	// undo the rollback
+	if err := config.Rollback(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"A": "3", "B": "added"}, true); err != nil {
+		t.Error(err)
+	}
+
+	if err := config.Rollback(ctx, 100); !errors.Is(err, ErrConfigVersion) {
+		t.Errorf("Expected version error, got %v", err)
+	}
+}
+
+func TestRollbackHistoryLimit(t *testing.T) {
+	ctx := context.TODO()
+	store := &ConfigStoreImpl{store: make(map[string]string)}
+	store.SetHistoryLimit(2)
+	for _, value := range []string{"1", "2", "3", "4"} {
+		if err := store.Set(ctx, "A", value, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Rollback(ctx, 1); !errors.Is(err, ErrConfigVersion) {
+		t.Errorf("Expected version error for trimmed history, got %v", err)
+	}
+	if err := store.Rollback(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get(ctx, "A"); err != nil || value != "2" {
+		t.Errorf("Expected '2', got '%s' (%v)", value, err)
+	}
+}
+
+func TestSnapshotUnversionedStore(t *testing.T) {
+	ctx := context.TODO()
+	store, err := NewConfigStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{ConfigStore: plainStore{store}}
+	if err := config.Set(ctx, "A", "1", true); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := config.Snapshot()
+	if err := config.Set(ctx, "A", "2", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := snapshot.Get(ctx, "A"); err != nil || value != "1" {
+		t.Errorf("Expected '1', got '%s' (%v)", value, err)
+	}
+	if config.Version() != 0 {
+		t.Errorf("Expected version 0 for unversioned store, got %d", config.Version())
+	}
+	if err := config.Rollback(ctx, 0); !errors.Is(err, ErrVersioningUnsupported) {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+}
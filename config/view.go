@@ -0,0 +1,138 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/myLogic207/gotils/config/internal/keytree"
+)
+
+// View returns a store scoped to prefix, reading and writing through to the config.
+// Keys of the view are relative to prefix, changes of either side are visible to the other.
+func (c *Config) View(prefix string) ConfigStore {
+	return &prefixStore{parent: c, prefix: normalizePrefix(prefix)}
+}
+
+// prefixStore is a live view on the keys below prefix of its parent store
+type prefixStore struct {
+	parent ConfigStore
+	prefix string
+}
+
+// fullKey validates a key of the view and returns the key in the parent store
+func (p *prefixStore) fullKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	key = strings.ToUpper(key)
+	if err := IsValidKey(key); err != nil { // check key is valid
+		return "", err
+	}
+	return keytree.Join(p.prefix, key), nil
+}
+
+func (p *prefixStore) Get(ctx context.Context, key string) (string, error) {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return "", err
+	}
+	return p.parent.Get(ctx, fullKey)
+}
+
+func (p *prefixStore) GetAll(ctx context.Context, key string) map[string]string {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return nil
+	}
+	return p.parent.GetAll(ctx, fullKey)
+}
+
+func (p *prefixStore) Set(ctx context.Context, key string, value string, force bool) error {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return p.parent.Set(ctx, fullKey, value, force)
+}
+
+func (p *prefixStore) Delete(ctx context.Context, key string) error {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return p.parent.Delete(ctx, fullKey)
+}
+
+func (p *prefixStore) DeletePrefix(ctx context.Context, prefix string) error {
+	fullKey, err := p.fullKey(prefix)
+	if err != nil {
+		return err
+	}
+	return p.parent.DeletePrefix(ctx, fullKey)
+}
+
+func (p *prefixStore) Has(ctx context.Context, key string) bool {
+	fullKey, err := p.fullKey(key)
+	if err != nil {
+		return false
+	}
+	return p.parent.Has(ctx, fullKey)
+}
+
+// Keys returns the keys below prefix relative to the view, a value stored at prefix itself is not part of the view
+func (p *prefixStore) Keys(ctx context.Context) []string {
+	keys := []string{}
+	for _, key := range p.parent.Keys(ctx) {
+		if rest, ok := keytree.CutPrefix(key, p.prefix); ok && rest != "" {
+			keys = append(keys, rest)
+		}
+	}
+	return keys
+}
+
+// Update runs fn in a transaction of the parent, so updates through the view stay atomic
+func (p *prefixStore) Update(ctx context.Context, fn func(tx Tx) error) error {
+	update := func(tx Tx) error {
+		return fn(&prefixTx{tx: tx, store: p})
+	}
+	if store, ok := p.parent.(TxStore); ok {
+		return store.Update(ctx, update)
+	}
+	return updateStore(ctx, p.parent, update)
+}
+
+// prefixTx maps the keys of a transaction on a view to the parent transaction
+type prefixTx struct {
+	tx    Tx
+	store *prefixStore
+}
+
+func (t *prefixTx) Get(ctx context.Context, key string) (string, error) {
+	fullKey, err := t.store.fullKey(key)
+	if err != nil {
+		return "", err
+	}
+	return t.tx.Get(ctx, fullKey)
+}
+
+func (t *prefixTx) Has(ctx context.Context, key string) bool {
+	fullKey, err := t.store.fullKey(key)
+	if err != nil {
+		return false
+	}
+	return t.tx.Has(ctx, fullKey)
+}
+
+func (t *prefixTx) Set(ctx context.Context, key string, value string) error {
+	fullKey, err := t.store.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return t.tx.Set(ctx, fullKey, value)
+}
+
+func (t *prefixTx) Delete(ctx context.Context, key string) error {
+	fullKey, err := t.store.fullKey(key)
+	if err != nil {
+		return err
+	}
+	return t.tx.Delete(ctx, fullKey)
+}
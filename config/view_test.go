@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestViewLive(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{
+		"writers": map[string]interface{}{
+			"file": map[string]interface{}{
+				"active": "true",
+				"prefix": "app",
+			},
+		},
+		"writersx": "other",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := config.View("writers/file/")
+	if value, err := view.Get(ctx, "active"); err != nil || value != "true" {
+		t.Errorf("Expected 'true', got '%s' (%v)", value, err)
+	}
+	if !slices.Equal(view.Keys(ctx), []string{"ACTIVE", "PREFIX"}) {
+		t.Errorf("Unexpected view keys %v", view.Keys(ctx))
+	}
+
+	// writes through the view reach the parent
+	if err := view.Set(ctx, "folder", "/tmp", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "WRITERS/FILE/FOLDER"); err != nil || value != "/tmp" {
+		t.Errorf("Expected '/tmp', got '%s' (%v)", value, err)
+	}
+
+	// writes to the parent are seen by the view
+	if err := config.Set(ctx, "WRITERS/FILE/PREFIX", "changed", true); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := view.Get(ctx, "PREFIX"); err != nil || value != "changed" {
+		t.Errorf("Expected 'changed', got '%s' (%v)", value, err)
+	}
+	if err := view.Delete(ctx, "ACTIVE"); err != nil {
+		t.Fatal(err)
+	}
+	if config.Has(ctx, "WRITERS/FILE/ACTIVE") {
+		t.Error("Delete through view did not reach parent")
+	}
+}
+
+func TestViewUpdate(t *testing.T) {
+	ctx := context.TODO()
+	config, err := WithInitialValues(ctx, map[string]interface{}{"scope": map[string]interface{}{"a": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := config.Version()
+	view := &Config{ConfigStore: config.View("SCOPE")}
+	err = view.Update(ctx, func(tx Tx) error {
+		if value, err := tx.Get(ctx, "A"); err != nil || value != "1" {
+			t.Errorf("Expected '1', got '%s' (%v)", value, err)
+		}
+		if err := tx.Set(ctx, "B", "2"); err != nil {
+			return err
+		}
+		return tx.Delete(ctx, "A")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.CompareMap(ctx, map[string]string{"SCOPE/B": "2"}, true); err != nil {
+		t.Error(err)
+	}
+	if config.Version() != version+1 {
+		t.Errorf("Expected a single version for the update, got %d -> %d", version, config.Version())
+	}
+}
+
+func TestNestedView(t *testing.T) {
+	ctx := context.TODO()
+	config, err := New(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer := &Config{ConfigStore: config.View("A")}
+	inner := outer.View("B")
+	if err := inner.Set(ctx, "C", "1", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := config.Get(ctx, "A/B/C"); err != nil || value != "1" {
+		t.Errorf("Expected '1', got '%s' (%v)", value, err)
+	}
+}
@@ -2,4 +2,8 @@ module github.com/myLogic207/gotils
 
 go 1.21.4
 
-require golang.org/x/sync v0.5.0
+require (
+	github.com/BurntSushi/toml v1.4.0
+	golang.org/x/sync v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
+)
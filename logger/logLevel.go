@@ -1,8 +1,12 @@
 package logger
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"strings"
+
+	"github.com/myLogic207/gotils/config"
 )
 
 var (
@@ -18,19 +22,6 @@ const (
 
 type LogLevel int
 
-func resolveLogLevel(rawLevel interface{}) (LogLevel, error) {
-	switch level := rawLevel.(type) {
-	case LogLevel:
-		return level, nil
-	case string:
-		return resolveLogLevelString(strings.ToUpper(level))
-	case int:
-		return resolveLogLevelInt(level)
-	default:
-		return 0, ErrInvalidLogLevel
-	}
-}
-
 func resolveLogLevelString(level string) (LogLevel, error) {
 	switch level {
 	case "DEBUG":
@@ -75,3 +66,36 @@ func (level LogLevel) Sprint() string {
 		return "UNKWN"
 	}
 }
+
+// String returns the level name without padding
+func (level LogLevel) String() string {
+	return strings.TrimSpace(level.Sprint())
+}
+
+// UnmarshalText parses a level name or a numeric level
+func (level *LogLevel) UnmarshalText(text []byte) error {
+	raw := strings.ToUpper(strings.TrimSpace(string(text)))
+	if resolved, err := resolveLogLevelString(raw); err == nil {
+		*level = resolved
+		return nil
+	}
+	number, err := strconv.Atoi(raw)
+	if err != nil {
+		return ErrInvalidLogLevel
+	}
+	resolved, err := resolveLogLevelInt(number)
+	if err != nil {
+		return err
+	}
+	*level = resolved
+	return nil
+}
+
+// normalizeLogLevel is the set hook of LEVEL, it rejects unknown levels and stores the level name
+func normalizeLogLevel(ctx context.Context, r config.Reader, key string, value string) (string, error) {
+	var level LogLevel
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return "", err
+	}
+	return level.String(), nil
+}
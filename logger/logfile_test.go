@@ -34,6 +34,9 @@ func TestLogFileRotate(t *testing.T) {
 		t.Fatalf("Error creating logger: %v", err)
 	}
 	logFile.Write([]byte("This is a test log message"))
+	if logfileConf.Has(context.TODO(), "FILENAME") {
+		t.Error("Expected the defaults not to be written to the options")
+	}
 
 	pwd, _ := os.Getwd()
 	filePath := path.Join(pwd, "test-logs", "test.log")
@@ -55,3 +58,38 @@ func TestLogFileRotate(t *testing.T) {
 		t.Fatalf("Log file was not rotated: %v", err)
 	}
 }
+
+func TestLogFileLiveOptions(t *testing.T) {
+	ctx := context.TODO()
+	parent, err := config.WithInitialValues(ctx, map[string]interface{}{
+		"WRITERS": map[string]interface{}{
+			"FILE": map[string]interface{}{"PREFIX": "live", "FOLDER": "test-logs-live"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.RemoveAll("test-logs-live"); err != nil {
+			t.Errorf("Error removing log file: %v", err)
+		}
+	})
+	logFile, err := NewLogFile(ctx, parent.View("WRITERS/FILE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close(ctx)
+	if value, err := logFile.config.Get(ctx, "SUFFIX"); err != nil || value != "log" {
+		t.Errorf("Expected default suffix, got '%s' (%v)", value, err)
+	}
+	// later changes of the parent are seen by the log file
+	if err := parent.Set(ctx, "WRITERS/FILE/ROTATING", "false", false); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := logFile.config.Get(ctx, "ROTATING"); err != nil || value != "false" {
+		t.Errorf("Expected the changed option, got '%s' (%v)", value, err)
+	}
+	if parent.Has(ctx, "WRITERS/FILE/SUFFIX") {
+		t.Error("Expected the defaults not to be written to the options")
+	}
+}
@@ -30,11 +30,11 @@ var (
 	ErrSetLogger      = errors.New("error setting logger")
 	invalidCharacters = []string{" ", "\t", "\n", "\r", "\v", "\f", ":", "=", "#", "\\", "\"", "'", "`", "/", ".", ",", ";", "!", "@", "$", "%", "^", "&", "*", "(", ")", "+", "-", "|", "[", "]", "{", "}", "<", ">", "?", "~"}
 	defaultLogConfig  = map[string]interface{}{
-		"PREFIX":      "LOGGER",
-		"FLAGS":       "date,time,microseconds,utc,msgprefix",
-		"COLUMLENGTH": 16,
-		"REPLACECHAR": "-",
-		"LEVEL":       "DEBUG",
+		"PREFIX":       "LOGGER",
+		"FLAGS":        "date,time,microseconds,utc,msgprefix",
+		"COLUMNLENGTH": 16,
+		"REPLACECHAR":  "-",
+		"LEVEL":        "DEBUG",
 		"WRITERS": map[string]interface{}{
 			"STDOUT": true,
 			"SYSLOG": false,
@@ -95,10 +95,21 @@ type logger struct {
 // }
 
 func Init(ctx context.Context, configOptions *config.Config) (Logger, error) {
-	cfg, err := config.WithInitialValuesAndOptions(ctx, defaultLogConfig, configOptions)
+	cfg, err := config.WithInitialValues(ctx, defaultLogConfig)
 	if err != nil {
 		return nil, err
 	}
+	// COLUMLENGTH is the misspelled name used by older configs
+	if err := cfg.Deprecate("COLUMLENGTH", "COLUMNLENGTH"); err != nil {
+		return nil, err
+	}
+	// levels are checked when they are set, so invalid levels are rejected while merging
+	if err := cfg.OnSet("LEVEL", normalizeLogLevel); err != nil {
+		return nil, err
+	}
+	if err := cfg.Merge(ctx, configOptions, true); err != nil {
+		return nil, err
+	}
 
 	wrapper := &logger{
 		config: cfg,
@@ -113,13 +124,14 @@ func Init(ctx context.Context, configOptions *config.Config) (Logger, error) {
 	return wrapper, nil
 }
 
+// parseLogLevel stores LEVEL as typed value, the level is already validated by its set hook
 func (l *logger) parseLogLevel(ctx context.Context) error {
-	rawLogLevel, _ := l.config.Get(ctx, "LEVEL")
-	logLevel, err := resolveLogLevel(rawLogLevel)
+	logLevel, err := config.Get[LogLevel](ctx, l.config, "LEVEL")
 	if err != nil {
-		return err
+		return errors.Join(ErrInvalidLogLevel, err)
 	}
-	if err := l.config.Set(ctx, "LEVEL", logLevel.Sprint(), true); err != nil {
+	// store the typed level, so Logf does not parse it for every message
+	if err := config.Set(ctx, l.config, "LEVEL", logLevel, true); err != nil {
 		return err
 	}
 	return nil
@@ -166,7 +178,7 @@ func (l *logger) UpdateLogger(ctx context.Context, config config.Config) error {
 }
 
 func (l *logger) LogMode(ctx context.Context, level LogLevel) Logger {
-	if err := l.config.Set(ctx, "LEVEL", level.Sprint(), true); err != nil {
+	if err := config.Set(ctx, l.config, "LEVEL", level, true); err != nil {
 		println("Error setting log level:", err)
 	}
 
@@ -178,7 +190,7 @@ func (l *logger) setLogger(ctx context.Context) error {
 		return err
 	}
 
-	prefixLengthRaw, _ := l.config.Get(ctx, "COLUMLENGTH")
+	prefixLengthRaw, _ := l.config.Get(ctx, "COLUMNLENGTH")
 	prefixLength, err := strconv.Atoi(prefixLengthRaw)
 	if err != nil {
 		return fmt.Errorf("cannot use %s as prefix length: %w", prefixLengthRaw, err)
@@ -222,13 +234,16 @@ func (l *logger) getLogFile(ctx context.Context) (*LogFile, error) {
 	if ok, err := strconv.ParseBool(fileActive); err != nil || !ok {
 		return nil, ErrFileNotActive
 	}
-	fileOptions, _ := l.config.GetConfig(ctx, "WRITERS/FILE")
+	// the view keeps the log file options live, later updates of the logger config are seen
+	fileOptions := l.config.View("WRITERS/FILE")
 	// check if prefix is not default
 	if prefix, _ := l.config.Get(ctx, "PREFIX"); prefix != defaultLogConfig["PREFIX"] {
 		// override logfile prefix with custom logger prefix
-		if err := fileOptions.Set(ctx, "PREFIX", prefix, true); err != nil {
+		override, err := config.WithInitialValues(ctx, map[string]interface{}{"PREFIX": prefix})
+		if err != nil {
 			return nil, errors.Join(ErrOpenLogFile, err)
 		}
+		fileOptions = config.WithFallback(override, fileOptions)
 	}
 	if file, err := NewLogFile(ctx, fileOptions); err != nil {
 		return nil, errors.Join(ErrOpenLogFile, err)
@@ -266,8 +281,7 @@ func formatPrefix(rawPrefix string, prefixLength int, replaceChar rune) string {
 }
 
 func (l *logger) Logf(ctx context.Context, level LogLevel, msg string, args ...any) {
-	loggerLevel, _ := l.config.Get(ctx, "LEVEL")
-	resolvedLevel, err := resolveLogLevel(loggerLevel)
+	resolvedLevel, err := config.Get[LogLevel](ctx, l.config, "LEVEL")
 	if err != nil {
 		resolvedLevel = LogLevel(0)
 	}
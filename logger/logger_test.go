@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
 	"strings"
@@ -80,3 +81,88 @@ func TestLogToFile(t *testing.T) {
 		}
 	}
 }
+
+func TestLogLevelText(t *testing.T) {
+	for raw, expected := range map[string]LogLevel{
+		"debug":  LevelDebug,
+		" INFO ": LevelInfo,
+		"WARN":   LevelWarn,
+		"300":    LevelError,
+	} {
+		var level LogLevel
+		if err := level.UnmarshalText([]byte(raw)); err != nil || level != expected {
+			t.Errorf("Expected %s for '%s', got %s (%v)", expected, raw, level, err)
+		}
+	}
+	var level LogLevel
+	if err := level.UnmarshalText([]byte("LOUD")); err != ErrInvalidLogLevel {
+		t.Errorf("Expected invalid level error, got %v", err)
+	}
+	if LevelInfo.String() != "INFO" {
+		t.Errorf("Expected 'INFO', got '%s'", LevelInfo.String())
+	}
+}
+
+func TestLogLevelTyped(t *testing.T) {
+	ctx := context.TODO()
+	cfg, err := config.WithInitialValues(ctx, map[string]interface{}{"LEVEL": "warn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set(ctx, cfg, "LEVEL", LevelError, true); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := cfg.GetTyped(ctx, "LEVEL"); err != nil || raw != LevelError {
+		t.Errorf("Expected typed level, got %v (%v)", raw, err)
+	}
+	if value, err := cfg.Get(ctx, "LEVEL"); err != nil || value != "ERROR" {
+		t.Errorf("Expected 'ERROR', got '%s' (%v)", value, err)
+	}
+}
+
+func TestDeprecatedColumnLength(t *testing.T) {
+	ctx := context.TODO()
+	options, err := config.WithInitialValues(ctx, map[string]interface{}{
+		"COLUMLENGTH": 6,
+		"WRITERS":     map[string]interface{}{"STDOUT": false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Init(ctx, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := l.(*logger).config
+	if value, err := cfg.Get(ctx, "COLUMNLENGTH"); err != nil || value != "6" {
+		t.Errorf("Expected '6', got '%s' (%v)", value, err)
+	}
+	if cfg.Has(ctx, "COLUMNLENGTH") != cfg.Has(ctx, "COLUMLENGTH") {
+		t.Error("Deprecated name does not resolve")
+	}
+}
+
+func TestInitLevelHook(t *testing.T) {
+	ctx := context.TODO()
+	options, err := config.WithInitialValues(ctx, map[string]interface{}{
+		"LEVEL":   "warn",
+		"WRITERS": map[string]interface{}{"STDOUT": false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Init(ctx, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := l.(*logger).config.Get(ctx, "LEVEL"); err != nil || value != "WARN" {
+		t.Errorf("Expected normalized level 'WARN', got '%s' (%v)", value, err)
+	}
+
+	if err := options.Set(ctx, "LEVEL", "LOUD", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Init(ctx, options); !errors.Is(err, ErrInvalidLogLevel) {
+		t.Errorf("Expected invalid level error, got %v", err)
+	}
+}
@@ -30,17 +30,23 @@ var (
 
 type LogFile struct {
 	file   *os.File
-	config *config.Config
+	config config.ConfigStore
 }
 
-func NewLogFile(ctx context.Context, options *config.Config) (*LogFile, error) {
-	cfg, err := config.WithInitialValuesAndOptions(ctx, defaultLogFileConfig, options)
+// NewLogFile creates a log file writer reading options, e.g. a live config.View of the logger config.
+// Keys missing in options are read from the defaults, options itself is not changed.
+func NewLogFile(ctx context.Context, options config.ConfigStore) (*LogFile, error) {
+	cfg, err := config.WithInitialValues(ctx, defaultLogFileConfig)
 	if err != nil {
 		return nil, errors.Join(ErrOpenLogFile, err)
 	}
+	var fileConfig config.ConfigStore = cfg
+	if options != nil {
+		fileConfig = config.WithFallback(options, cfg)
+	}
 
 	writer := &LogFile{
-		config: cfg,
+		config: fileConfig,
 	}
 
 	if err := writer.generateLogFile(ctx); err != nil {